@@ -4,8 +4,10 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -15,61 +17,88 @@ import (
 	"time"
 )
 
-// Package manager settings accessors
-var wingetEnabled = true
-var chocoEnabled = true
-
-func getWingetEnabled() bool {
-	return wingetEnabled
-}
-
-func getChocoEnabled() bool {
-	return chocoEnabled
-}
-
-func setWingetEnabled(enabled bool) {
-	wingetEnabled = enabled
-}
-
-func setChocoEnabled(enabled bool) {
-	chocoEnabled = enabled
-}
-
 type AppManager struct {
 	db                  *sql.DB
-	wingetManager       *WingetManager
-	chocoManager        *ChocolateyManager
+	backends            *BackendRegistry
 	currentApps         []*AppInfo
 	allApps             []*AppInfo // Store all unfiltered apps
 	installedApps       []*AppInfo
+	upgradableApps      []*AppInfo // Installed apps with a newer version available
 	savedApps           []*AppInfo
 	allLists            []*AppList // Store all available lists
 	currentList         *AppList   // Currently selected list
 	currentSourceFilter string     // Track current source filter
-	currentViewFilter   string     // Track current view filter (All Results, Installed Only, Saved Apps)
+	currentTagFilter    string     // Track current tag filter, "All Tags" or a tag saved apps must have
+	allTags             []string   // Tags in use by any saved app
+	listTags            []string   // Tags in use by a saved app in the current list
+	currentViewFilter   string     // Track current view filter (All Results, Installed Only, Saved Apps, Updates Available)
 	currentSearchQuery  string     // Track current search query
 	isSearchMode        bool       // Track if we're showing search results
 	isLoading           bool       // Track if we're currently loading/searching
 	mutex               sync.RWMutex
 	callbacks           []func()
+
+	// Cancellation of running backend commands. Kept apart from mutex because
+	// searches and refreshes hold mutex for their whole duration.
+	ctx        context.Context // Cancelled by Shutdown
+	cancel     context.CancelFunc
+	opMutex    sync.Mutex
+	operations map[string]*operation // Running operations by key, e.g. "search"
+	runningOps sync.WaitGroup
+
+	// Live installation output, keyed like install operations
+	installProgress   map[string]*InstallProgress
+	progressCallbacks []func()
+
+	detailsCache map[string]*PackageDetails // Fetched package details by Source|PackageID, guarded by opMutex
+
+	searchCacheTTL time.Duration // How long cached search results count as fresh, guarded by opMutex
+
+	searchGeneration int                   // Incremented by every search so late results of an older one are dropped
+	searchStatus     []BackendSearchStatus // How each backend answered the current search
+
+	recorder *RecordingRunner // Records every backend command while set, guarded by opMutex
+
+	pendingReboots []*AppInfo // Apps installed this session that need a restart to finish, guarded by opMutex
+
+	queue *InstallQueue // Every install waits its turn here, limited per backend
+
+	settings *Settings // Preferences as last applied, guarded by opMutex
+}
+
+// operation is a cancellable backend call tracked by the AppManager
+type operation struct {
+	cancel context.CancelFunc
 }
 
 func NewAppManager(db *sql.DB) *AppManager {
+	ctx, cancel := context.WithCancel(context.Background())
+
 	am := &AppManager{
 		db:                  db,
-		wingetManager:       &WingetManager{},
-		chocoManager:        &ChocolateyManager{},
+		backends:            newDefaultBackendRegistry(),
 		currentApps:         make([]*AppInfo, 0),
 		allApps:             make([]*AppInfo, 0),
 		installedApps:       make([]*AppInfo, 0),
+		upgradableApps:      make([]*AppInfo, 0),
 		savedApps:           make([]*AppInfo, 0),
 		allLists:            make([]*AppList, 0),
 		currentSourceFilter: "All Sources",    // Default filter
+		currentTagFilter:    "All Tags",       // Default filter
 		currentViewFilter:   "Installed Only", // Default to installed
 		isSearchMode:        false,
 		isLoading:           false,
 		callbacks:           make([]func(), 0),
+		ctx:                 ctx,
+		cancel:              cancel,
+		operations:          make(map[string]*operation),
+		installProgress:     make(map[string]*InstallProgress),
+		detailsCache:        make(map[string]*PackageDetails),
+		searchCacheTTL:      defaultSearchCacheTTL,
+		settings:            DefaultSettings(),
 	}
+	am.queue = NewInstallQueue(ctx, am.runInstall)
+	am.queue.SetOnChange(am.notifyProgressCallbacks)
 
 	// Load lists and set default list on startup
 	go func() {
@@ -80,11 +109,14 @@ func NewAppManager(db *sql.DB) *AppManager {
 		}()
 		am.LoadLists()
 		// Set default list as current
-		if len(am.allLists) > 0 {
-			am.SetCurrentList(am.allLists[0]) // Default list should be first
+		if list := am.DefaultList(); list != nil {
+			am.SetCurrentList(list)
 		}
 	}()
 
+	// Package managers are detected by DetectBackends once the settings with
+	// their executable overrides have been applied
+
 	// REMOVED AUTO-LOADING TO PREVENT UI DEADLOCK
 	// Auto-loading will be triggered by user action (refresh button) instead
 
@@ -112,13 +144,16 @@ func (am *AppManager) notifyCallbacks() {
 	}
 }
 
-func (am *AppManager) SearchApps(query string) error {
+func (am *AppManager) SearchApps(ctx context.Context, query string) error {
 	// Handle empty query by clearing search mode
 	if strings.TrimSpace(query) == "" {
 		am.ClearSearch()
 		return nil
 	}
 
+	ctx, done := am.startOperation(ctx, "search")
+	defer done()
+
 	// Set loading state BEFORE acquiring mutex to avoid deadlock
 	am.SetLoading(true)
 
@@ -131,15 +166,22 @@ func (am *AppManager) SearchApps(query string) error {
 
 	// Set search mode
 	am.isSearchMode = true
+	am.searchGeneration++
+	am.searchStatus = nil
 
 	// Determine which apps to search based on current view filter
 	switch am.currentViewFilter {
 	case "Saved Apps":
-		// Search within saved apps only
+		// Search within saved apps only, including their tags
 		for _, app := range am.savedApps {
 			if strings.Contains(strings.ToLower(app.Name), strings.ToLower(query)) ||
 				strings.Contains(strings.ToLower(app.PackageID), strings.ToLower(query)) {
 				searchApps = append(searchApps, app)
+			} else if tag := matchingTag(app.Tags, query); tag != "" {
+				// A copy, so the match is not shown once the search is cleared
+				match := *app
+				match.Match = "Tag: " + tag
+				searchApps = append(searchApps, &match)
 			}
 		}
 		am.allApps = searchApps // Set search results as all apps for filtering
@@ -152,115 +194,1027 @@ func (am *AppManager) SearchApps(query string) error {
 			}
 		}
 		am.allApps = searchApps // Set search results as all apps for filtering
+	case "Updates Available":
+		// Search within upgradable apps only
+		for _, app := range am.upgradableApps {
+			if strings.Contains(strings.ToLower(app.Name), strings.ToLower(query)) ||
+				strings.Contains(strings.ToLower(app.PackageID), strings.ToLower(query)) {
+				searchApps = append(searchApps, app)
+			}
+		}
+		am.allApps = searchApps // Set search results as all apps for filtering
 	default: // "All Results"
-		// Search with winget if available AND enabled
-		if am.wingetManager.IsAvailable() && getWingetEnabled() {
-			apps, err := am.wingetManager.Search(query)
-			if err == nil {
-				searchApps = append(searchApps, apps...)
+		// Backends are searched without holding the mutex so results can be
+		// shown as each one answers
+		generation := am.searchGeneration
+		am.allApps = nil
+		am.applyAllFilters()
+		am.mutex.Unlock()
+
+		if err := am.searchAllBackends(ctx, query, generation); err != nil {
+			// A cancelled search goes back to the normal view instead of showing partial results
+			am.mutex.Lock()
+			if am.searchGeneration == generation {
+				am.isSearchMode = false
+				am.currentSearchQuery = ""
+				am.searchStatus = nil
+				am.isLoading = false
+				am.applyAllFilters()
+			}
+			am.mutex.Unlock()
+			am.SetLoading(false)
+			return err
+		}
+
+		am.mutex.Lock()
+	}
+
+	// Clear loading state BEFORE applying filters so UI can display results
+	am.isLoading = false
+
+	// Apply current source filter to search results
+	am.applyAllFilters()
+
+	am.mutex.Unlock()
+
+	// Clear loading state after everything is done
+	am.SetLoading(false)
+
+	return nil
+}
+
+// backendSearchResult is one backend's answer to a search
+type backendSearchResult struct {
+	index int // Position of the backend in searchStatus
+	apps  []*AppInfo
+	stale bool
+	err   error
+}
+
+// searchAllBackends searches every enabled backend at once and merges their
+// results into allApps as they arrive. Results are dropped if a newer search
+// has started in the meantime. Only a cancelled search returns an error.
+func (am *AppManager) searchAllBackends(ctx context.Context, query string, generation int) error {
+	backends := am.backends.All()
+	statuses := make([]BackendSearchStatus, len(backends))
+	results := make(chan backendSearchResult)
+	var wg sync.WaitGroup
+
+	for i, backend := range backends {
+		statuses[i] = BackendSearchStatus{
+			Source:      backend.Name(),
+			DisplayName: backend.DisplayName(),
+			State:       SearchRunning,
+		}
+		if !am.backends.IsEnabled(backend.Name()) {
+			statuses[i].State = SearchDisabled
+			statuses[i].Message = "turned off in settings"
+			continue
+		}
+
+		wg.Add(1)
+		go func(index int, backend PackageManager) {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					results <- backendSearchResult{index: index, err: fmt.Errorf("search crashed: %v", r)}
+				}
+			}()
+
+			if !am.backends.IsAvailable(ctx, backend.Name()) {
+				results <- backendSearchResult{index: index, err: errBackendUnavailable}
+				return
 			}
+			apps, stale, err := am.searchBackend(ctx, backend, query)
+			results <- backendSearchResult{index: index, apps: apps, stale: stale, err: err}
+		}(i, backend)
+	}
+
+	am.mutex.Lock()
+	if am.searchGeneration == generation {
+		am.searchStatus = statuses
+		am.notifyCallbacks()
+	}
+	am.mutex.Unlock()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	for result := range results {
+		if ctx.Err() != nil {
+			continue // Drain the remaining backends
 		}
 
-		// Search with chocolatey if available AND enabled
-		if am.chocoManager.IsAvailable() && getChocoEnabled() {
-			apps, err := am.chocoManager.Search(query)
-			if err == nil {
-				searchApps = append(searchApps, apps...)
+		backend := backends[result.index]
+		if result.stale {
+			am.revalidateSearch(backend, query)
+		}
+
+		am.mutex.Lock()
+		if am.isSearchMode && am.searchGeneration == generation {
+			finishSearchStatus(&am.searchStatus[result.index], len(result.apps), result.err)
+			if result.err == nil {
+				am.markSavedStatus(result.apps)
+				am.allApps = append(am.allApps, result.apps...)
 			}
+			am.applyAllFilters()
+		}
+		am.mutex.Unlock()
+	}
+
+	return ctx.Err()
+}
+
+// GetSearchStatus returns how each backend answered the current search, or
+// nil when no backend search is shown
+func (am *AppManager) GetSearchStatus() []BackendSearchStatus {
+	am.mutex.RLock()
+	defer am.mutex.RUnlock()
+
+	if !am.isSearchMode {
+		return nil
+	}
+	return append([]BackendSearchStatus(nil), am.searchStatus...)
+}
+
+// searchBackend searches one backend, answering from the search cache when it
+// holds the query. stale is true when the cached results have outlived the
+// cache TTL and should be refreshed.
+func (am *AppManager) searchBackend(ctx context.Context, backend PackageManager, query string) (apps []*AppInfo, stale bool, err error) {
+	ttl := am.SearchCacheTTL()
+	key := normalizeSearchQuery(query)
+
+	if ttl > 0 {
+		cached, fetchedAt, found, err := GetCachedSearch(am.db, backend.Name(), key)
+		if err == nil && found {
+			return cached, !isSearchCacheFresh(fetchedAt, time.Now(), ttl), nil
 		}
+	}
+
+	apps, err = backend.Search(ctx, query)
+	if err != nil {
+		return nil, false, err
+	}
+	if ttl > 0 {
+		// A failed cache write only costs a slower search next time
+		SaveCachedSearch(am.db, backend.Name(), key, apps)
+	}
+	return apps, false, nil
+}
+
+// revalidateSearch runs a search again in the background to replace stale
+// cached results. The fresh results are shown if the search is still on screen.
+func (am *AppManager) revalidateSearch(backend PackageManager, query string) {
+	key := normalizeSearchQuery(query)
+	opKey := "search-refresh:" + backend.Name() + "|" + key
+	if am.isOperationRunning(opKey) {
+		return
+	}
+	ctx, done := am.startOperation(am.ctx, opKey)
+
+	go func() {
+		defer done()
+		defer func() {
+			if r := recover(); r != nil {
+				// Handle panic gracefully
+			}
+		}()
+
+		apps, err := backend.Search(ctx, query)
+		if err != nil {
+			return
+		}
+		SaveCachedSearch(am.db, backend.Name(), key, apps)
+
+		am.mutex.Lock()
+		defer am.mutex.Unlock()
+
+		if !am.isSearchMode || am.currentViewFilter != "All Results" ||
+			normalizeSearchQuery(am.currentSearchQuery) != key {
+			return
+		}
+
+		// Swap this backend's results for the fresh ones
+		refreshed := make([]*AppInfo, 0, len(am.allApps))
+		for _, app := range am.allApps {
+			if app.Source != backend.Name() {
+				refreshed = append(refreshed, app)
+			}
+		}
+		am.markSavedStatus(apps)
+		am.allApps = append(refreshed, apps...)
+		am.applyAllFilters()
+	}()
+}
+
+// SearchCacheTTL is how long cached search results are served without
+// refreshing them. Zero means searches are not cached.
+func (am *AppManager) SearchCacheTTL() time.Duration {
+	am.opMutex.Lock()
+	defer am.opMutex.Unlock()
+	return am.searchCacheTTL
+}
+
+func (am *AppManager) SetSearchCacheTTL(ttl time.Duration) {
+	am.opMutex.Lock()
+	defer am.opMutex.Unlock()
+	am.searchCacheTTL = ttl
+}
+
+// ClearSearchCache forgets every cached search so the next searches ask the
+// package managers again
+func (am *AppManager) ClearSearchCache() error {
+	return ClearSearchCache(am.db)
+}
+
+func (am *AppManager) RefreshInstalledApps(ctx context.Context) error {
+	ctx, done := am.startOperation(ctx, "refresh")
+	defer done()
+
+	// Set loading state BEFORE acquiring mutex to avoid deadlock
+	am.SetLoading(true)
+
+	am.mutex.Lock()
+
+	var allApps []*AppInfo
+
+	// Exit search mode when refreshing
+	am.isSearchMode = false
+
+	// Get installed apps from every backend that is available AND enabled
+	for _, backend := range am.backends.Active(ctx) {
+		apps, err := backend.GetInstalledApps(ctx)
+		if err == nil {
+			allApps = append(allApps, apps...)
+		}
+	}
+
+	// Mark saved status and carry over known upgrades
+	am.markSavedStatus(allApps)
+	am.markUpgradeStatus(allApps)
+
+	am.installedApps = allApps
+	am.allApps = allApps // Store all apps for filtering
+	am.markInstalledStatus(am.savedApps)
+
+	// Clear loading state BEFORE applying filters so UI can display results
+	am.isLoading = false
+
+	// Apply current source filter to refresh results
+	am.applyAllFilters()
+
+	am.mutex.Unlock()
+
+	// Clear loading state after everything is done
+	am.SetLoading(false)
+
+	return nil
+}
+
+func (am *AppManager) RefreshUpgradableApps(ctx context.Context) error {
+	ctx, done := am.startOperation(ctx, "upgrades")
+	defer done()
+
+	// Set loading state BEFORE acquiring mutex to avoid deadlock
+	am.SetLoading(true)
+
+	am.mutex.Lock()
+
+	var upgradable []*AppInfo
+
+	// Exit search mode when refreshing
+	am.isSearchMode = false
+
+	// Get pending upgrades from every backend that is available AND enabled
+	for _, backend := range am.backends.Active(ctx) {
+		apps, err := backend.GetUpgradableApps(ctx)
+		if err == nil {
+			upgradable = append(upgradable, apps...)
+		}
+	}
+
+	// Mark saved status
+	am.markSavedStatus(upgradable)
+
+	am.upgradableApps = upgradable
+
+	// Show the available version on the matching installed apps too
+	am.markUpgradeStatus(am.installedApps)
+
+	// Clear loading state BEFORE applying filters so UI can display results
+	am.isLoading = false
+
+	am.applyAllFilters()
+
+	am.mutex.Unlock()
+
+	// Clear loading state after everything is done
+	am.SetLoading(false)
+
+	return nil
+}
+
+// InstallApp queues an installation of app and waits for it to finish. The
+// install is cancelled when ctx is done.
+func (am *AppManager) InstallApp(ctx context.Context, app *AppInfo) error {
+	id := am.queue.Enqueue(app)
+	err := am.queue.Wait(ctx, id)
+	if ctx.Err() != nil {
+		am.queue.Cancel(id)
+		return fmt.Errorf("installation of %s was cancelled: %w", app.Name, ctx.Err())
+	}
+	return err
+}
+
+// runInstall installs app right away. The install queue calls it once the
+// app's turn has come.
+func (am *AppManager) runInstall(ctx context.Context, app *AppInfo) error {
+	key := installOperationKey(app)
+	ctx, done := am.startOperation(ctx, key)
+	defer done()
+
+	startedAt := time.Now()
+	am.resetInstallProgress(key)
+	backend, err := am.backendFor(ctx, app.Source)
+	if err == nil {
+		err = backend.Install(ctx, app.PackageID, installOptionsFor(app), func(event ProgressEvent) {
+			am.recordInstallProgress(key, event)
+		})
+	}
+	// An app that was already installed or only needs a restart still counts
+	succeeded := err == nil || IsSoftFailure(err)
+	if succeeded && app.Pinned {
+		// Keep later upgrades from moving the app off the required version
+		if pinErr := backend.Pin(ctx, app.PackageID, requiredVersion(app)); pinErr != nil {
+			am.recordInstallProgress(key, ProgressEvent{Phase: PhaseInstalling, Percent: -1, Line: fmt.Sprintf("Failed to pin %s: %v", app.Name, pinErr)})
+			if hint := ErrorHint(pinErr); hint != "" {
+				am.recordInstallProgress(key, ProgressEvent{Phase: PhaseInstalling, Percent: -1, Line: hint})
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		am.recordInstallProgress(key, ProgressEvent{Phase: PhaseCancelled, Percent: -1, Line: "Installation cancelled"})
+		err = fmt.Errorf("installation of %s was cancelled: %w", app.Name, ctx.Err())
+		am.recordInstallHistory(app, startedAt, OutcomeCancelled, err)
+		return err
+	}
+	switch {
+	case err == nil:
+		am.recordInstallProgress(key, ProgressEvent{Phase: PhaseDone, Percent: 1})
+	case errors.Is(err, ErrRebootRequired):
+		am.recordInstallProgress(key, ProgressEvent{Phase: PhaseRestart, Percent: 1, Line: err.Error()})
+		am.addPendingReboot(app)
+	case succeeded:
+		am.recordInstallProgress(key, ProgressEvent{Phase: PhaseDone, Percent: 1, Line: err.Error()})
+	default:
+		am.recordInstallProgress(key, ProgressEvent{Phase: PhaseFailed, Percent: -1, Line: err.Error()})
+		if hint := ErrorHint(err); hint != "" {
+			am.recordInstallProgress(key, ProgressEvent{Phase: PhaseFailed, Percent: -1, Line: hint})
+		}
+	}
+
+	switch {
+	case errors.Is(err, ErrRebootRequired):
+		am.recordInstallHistory(app, startedAt, OutcomeRestart, err)
+	case succeeded:
+		am.recordInstallHistory(app, startedAt, OutcomeSucceeded, err)
+	default:
+		am.recordInstallHistory(app, startedAt, OutcomeFailed, err)
+	}
+
+	if succeeded {
+		// Refresh installed apps after successful installation
+		go am.RefreshInstalledApps(am.ctx)
+	}
+
+	return err
+}
+
+// recordInstallHistory adds a finished installation of app to the install
+// history, together with the output captured while it ran
+func (am *AppManager) recordInstallHistory(app *AppInfo, startedAt time.Time, outcome InstallOutcome, err error) {
+	record := &InstallRecord{
+		Name:       app.Name,
+		PackageID:  app.PackageID,
+		Source:     app.Source,
+		Version:    requiredVersion(app),
+		ListID:     app.ListID,
+		StartedAt:  startedAt,
+		FinishedAt: time.Now(),
+		Outcome:    outcome,
+	}
+	if record.Version == "" {
+		record.Version = "latest"
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	if progress := am.GetInstallProgress(app); progress != nil {
+		record.Log = strings.Join(progress.Log, "\n")
+	}
+
+	am.mutex.RLock()
+	for _, list := range am.allLists {
+		if list.ID == app.ListID {
+			record.ListName = list.Name
+		}
+	}
+	am.mutex.RUnlock()
+
+	// The history is informational, a failed write must not fail the install
+	AddInstallRecord(am.db, record)
+}
+
+// GetInstallHistory returns the recorded installations matching filter, newest first
+func (am *AppManager) GetInstallHistory(filter HistoryFilter) ([]*InstallRecord, error) {
+	return GetInstallHistory(am.db, filter)
+}
+
+func installOptionsFor(app *AppInfo) InstallOptions {
+	options := app.Options
+	options.Version = requiredVersion(app)
+	return options
+}
+
+// SetInstallOptions changes how a saved app is installed: its version, whether
+// it is pinned and the installer options. Installed apps are pinned or
+// unpinned right away.
+func (am *AppManager) SetInstallOptions(ctx context.Context, app *AppInfo, version string, pinned bool, options InstallOptions) error {
+	if strings.TrimSpace(version) == "" {
+		version = "latest"
+	}
+	// The version is kept on the app, not in its options
+	options.Version = ""
+
+	wasPinned := app.Pinned
+	if err := UpdateSavedAppInstallOptions(am.db, app.ListID, app.Source, app.PackageID, version, pinned, options); err != nil {
+		return err
+	}
+	app.RequiredVersion = version
+	app.Pinned = pinned
+	app.Options = options
+
+	var err error
+	if app.IsInstalled && pinned != wasPinned {
+		backend, backendErr := am.backendFor(ctx, app.Source)
+		if backendErr != nil {
+			err = backendErr
+		} else if pinned {
+			err = backend.Pin(ctx, app.PackageID, requiredVersion(app))
+		} else {
+			err = backend.Unpin(ctx, app.PackageID)
+		}
+	}
+
+	am.LoadSavedApps()
+	am.mutex.Lock()
+	am.applyAllFilters()
+	am.mutex.Unlock()
+
+	return err
+}
+
+func (am *AppManager) UninstallApp(ctx context.Context, app *AppInfo) error {
+	ctx, done := am.startOperation(ctx, "uninstall:"+app.Source+"|"+app.PackageID)
+	defer done()
+
+	backend, err := am.backendFor(ctx, app.Source)
+	if err == nil {
+		err = backend.Uninstall(ctx, app.PackageID)
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("removal of %s was cancelled: %w", app.Name, ctx.Err())
+	}
+
+	if err == nil {
+		// Refresh installed apps after successful removal
+		go am.RefreshInstalledApps(am.ctx)
+	}
+
+	return err
+}
+
+func (am *AppManager) UpgradeApp(ctx context.Context, app *AppInfo) error {
+	err := am.upgradeApp(ctx, app)
+
+	if err == nil {
+		// Refresh installed apps and pending upgrades to pick up the new version
+		go am.refreshAfterUpgrade(am.ctx)
+	}
+
+	return err
+}
+
+func (am *AppManager) upgradeApp(ctx context.Context, app *AppInfo) error {
+	ctx, done := am.startOperation(ctx, "upgrade:"+app.Source+"|"+app.PackageID)
+	defer done()
+
+	backend, err := am.backendFor(ctx, app.Source)
+	if err == nil {
+		err = backend.Upgrade(ctx, app.PackageID)
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("upgrade of %s was cancelled: %w", app.Name, ctx.Err())
+	}
+	if errors.Is(err, ErrRebootRequired) {
+		am.addPendingReboot(app)
+	}
+	return err
+}
+
+// addPendingReboot remembers that app needs a restart to finish installing
+func (am *AppManager) addPendingReboot(app *AppInfo) {
+	am.opMutex.Lock()
+	defer am.opMutex.Unlock()
+
+	for _, pending := range am.pendingReboots {
+		if pending.Source == app.Source && pending.PackageID == app.PackageID {
+			return
+		}
+	}
+	am.pendingReboots = append(am.pendingReboots, app)
+}
+
+// PendingReboots returns the apps waiting for a restart to finish installing
+func (am *AppManager) PendingReboots() []*AppInfo {
+	am.opMutex.Lock()
+	defer am.opMutex.Unlock()
+	return append([]*AppInfo(nil), am.pendingReboots...)
+}
+
+// RestartComputer restarts Windows to finish the pending installations
+func (am *AppManager) RestartComputer() error {
+	if err := restartComputer(); err != nil {
+		return fmt.Errorf("failed to restart: %v", err)
+	}
+
+	am.opMutex.Lock()
+	am.pendingReboots = nil
+	am.opMutex.Unlock()
+	return nil
+}
+
+func (am *AppManager) refreshAfterUpgrade(ctx context.Context) {
+	am.RefreshInstalledApps(ctx)
+	am.RefreshUpgradableApps(ctx)
+}
+
+// UpgradeSummary counts how the apps of an Upgrade All ended
+type UpgradeSummary struct {
+	Upgraded        int        // Upgraded and ready to use
+	RestartRequired []*AppInfo // Upgraded, but need a restart to finish
+	NoUpgrade       int        // The backend found no applicable upgrade after all
+	Pinned          int        // Skipped because they are pinned
+}
+
+// Changed reports whether any app was actually upgraded
+func (s UpgradeSummary) Changed() bool {
+	return s.Upgraded+len(s.RestartRequired) > 0
+}
+
+// Summary counts the outcomes, e.g. "3 upgraded, 1 pinned and skipped"
+func (s UpgradeSummary) Summary() string {
+	var parts []string
+	if s.Upgraded > 0 {
+		parts = append(parts, fmt.Sprintf("%d upgraded", s.Upgraded))
+	}
+	if n := len(s.RestartRequired); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d upgraded but waiting for a restart", n))
+	}
+	if s.NoUpgrade > 0 {
+		parts = append(parts, fmt.Sprintf("%d had no applicable upgrade", s.NoUpgrade))
+	}
+	if s.Pinned > 0 {
+		parts = append(parts, fmt.Sprintf("%d pinned and skipped", s.Pinned))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// UpgradeAllApps upgrades every app in the "Updates Available" view, continuing
+// past individual failures and reporting them together at the end. The summary
+// counts how the other apps ended, also when some failed.
+func (am *AppManager) UpgradeAllApps(ctx context.Context) (UpgradeSummary, error) {
+	var summary UpgradeSummary
+	apps := am.GetUpgradableApps()
+
+	// Pinned apps stay at the version they were pinned to
+	pinned := make(map[string]bool)
+	am.mutex.RLock()
+	for _, saved := range am.savedApps {
+		if saved.Pinned {
+			pinned[saved.Source+"|"+saved.PackageID] = true
+		}
+	}
+	am.mutex.RUnlock()
+
+	var failures []string
+	for _, app := range apps {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		if app.Pinned || pinned[app.Source+"|"+app.PackageID] {
+			summary.Pinned++
+			continue
+		}
+		err := am.upgradeApp(ctx, app)
+		switch {
+		case err == nil:
+			summary.Upgraded++
+		case errors.Is(err, ErrRebootRequired):
+			summary.RestartRequired = append(summary.RestartRequired, app)
+		case IsSoftFailure(err):
+			summary.NoUpgrade++
+		default:
+			failures = append(failures, fmt.Sprintf("%s: %v", app.Name, err))
+		}
+	}
+
+	if summary.Changed() || len(failures) > 0 {
+		am.refreshAfterUpgrade(ctx)
+	}
+
+	if len(failures) > 0 {
+		return summary, fmt.Errorf("failed to upgrade %d of %d applications:\n%s",
+			len(failures), len(apps)-summary.Pinned, strings.Join(failures, "\n"))
+	}
+
+	return summary, nil
+}
+
+// backendFor looks up the package manager that owns the given source
+func (am *AppManager) backendFor(ctx context.Context, source string) (PackageManager, error) {
+	backend, ok := am.backends.Get(source)
+	if !ok {
+		return nil, fmt.Errorf("unknown package source: %s", source)
+	}
+	if !am.backends.IsAvailable(ctx, backend.Name()) {
+		return nil, fmt.Errorf("%s is not available", source)
+	}
+	return backend, nil
+}
+
+func (am *AppManager) Backends() *BackendRegistry {
+	return am.backends
+}
+
+// Settings returns a copy of the settings in effect
+func (am *AppManager) Settings() *Settings {
+	am.opMutex.Lock()
+	defer am.opMutex.Unlock()
+	return am.settings.Clone()
+}
+
+// DetectBackends detects the installed package managers once in the
+// background. Call it after LoadSettings so executable overrides are used.
+func (am *AppManager) DetectBackends() {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				// Handle panic gracefully
+			}
+		}()
+		am.backends.Detect(am.ctx)
+	}()
+}
+
+// LoadSettings applies the settings stored in the database. Called once at
+// startup, before the window is shown.
+func (am *AppManager) LoadSettings() (*Settings, error) {
+	settings, err := LoadSettings(am.db)
+	if err != nil {
+		return am.Settings(), err
+	}
+	if err := settings.Validate(am.backendSources()); err != nil {
+		// Better to start with the defaults than with settings that cannot work
+		defaults := DefaultSettings()
+		if applyErr := am.applySettings(defaults); applyErr != nil {
+			return defaults, applyErr
+		}
+		return defaults, fmt.Errorf("stored settings are invalid, using the defaults: %w", err)
+	}
+	return settings, am.applySettings(settings)
+}
+
+// UpdateSettings changes a copy of the settings through update, then
+// validates, stores and applies the result. Nothing changes if the new
+// settings are invalid.
+func (am *AppManager) UpdateSettings(update func(settings *Settings)) error {
+	settings := am.Settings()
+	update(settings)
+	if err := settings.Validate(am.backendSources()); err != nil {
+		return err
+	}
+	if err := SaveSettings(am.db, settings); err != nil {
+		return fmt.Errorf("failed to save settings: %w", err)
+	}
+	return am.applySettings(settings)
+}
+
+// ResetSettings forgets the stored settings and goes back to the defaults
+func (am *AppManager) ResetSettings() (*Settings, error) {
+	if err := ResetSettings(am.db); err != nil {
+		return am.Settings(), fmt.Errorf("failed to reset settings: %w", err)
+	}
+	settings := DefaultSettings()
+	return settings, am.applySettings(settings)
+}
+
+func (am *AppManager) backendSources() []string {
+	var sources []string
+	for _, backend := range am.backends.All() {
+		sources = append(sources, backend.Name())
+	}
+	return sources
+}
+
+// applySettings makes validated settings take effect. The theme is applied
+// by the UI.
+func (am *AppManager) applySettings(settings *Settings) error {
+	am.opMutex.Lock()
+	am.settings = settings.Clone()
+	am.opMutex.Unlock()
+
+	// Enable backends before disabling others so one always stays enabled
+	for _, enable := range []bool{true, false} {
+		for _, source := range am.backendSources() {
+			if settings.IsBackendEnabled(source) == enable && am.backends.IsEnabled(source) != enable {
+				if err := am.backends.SetEnabled(source, enable); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, source := range am.backendSources() {
+		if path := settings.ExecutablePaths[source]; path != am.backends.ExecutablePath(source) {
+			if err := am.backends.SetExecutablePath(source, path); err != nil {
+				return err
+			}
+		}
+		am.SetInstallConcurrency(source, settings.Concurrency(source))
+	}
+	am.SetSearchCacheTTL(settings.SearchCacheTTL)
+
+	if settings.RecordCommands && !am.IsRecording() {
+		if _, err := am.StartRecording(); err != nil {
+			return err
+		}
+	} else if !settings.RecordCommands && am.IsRecording() {
+		am.StopRecording()
+	}
+	return nil
+}
+
+func installOperationKey(app *AppInfo) string {
+	return "install:" + app.Source + "|" + app.PackageID
+}
+
+// startOperation derives a cancellable context for a long running backend call
+// and tracks it under key. The context is also cancelled by Shutdown. The
+// returned func must be called once the operation has finished.
+func (am *AppManager) startOperation(ctx context.Context, key string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	stopOnShutdown := context.AfterFunc(am.ctx, cancel)
+
+	op := &operation{cancel: cancel}
+	am.opMutex.Lock()
+	am.operations[key] = op
+	am.opMutex.Unlock()
+	am.runningOps.Add(1)
+
+	return ctx, func() {
+		am.opMutex.Lock()
+		if am.operations[key] == op {
+			delete(am.operations, key)
+		}
+		am.opMutex.Unlock()
+
+		stopOnShutdown()
+		cancel()
+		am.runningOps.Done()
+	}
+}
+
+func (am *AppManager) cancelOperation(key string) bool {
+	am.opMutex.Lock()
+	defer am.opMutex.Unlock()
+
+	op, ok := am.operations[key]
+	if ok {
+		op.cancel()
+	}
+	return ok
+}
+
+func (am *AppManager) isOperationRunning(key string) bool {
+	am.opMutex.Lock()
+	defer am.opMutex.Unlock()
+
+	_, ok := am.operations[key]
+	return ok
+}
+
+// CancelSearch stops the running search, if any
+func (am *AppManager) CancelSearch() {
+	am.cancelOperation("search")
+}
 
-		// Mark saved status
-		am.markSavedStatus(searchApps)
-		am.allApps = searchApps // Store all apps
+// CancelInstall stops a running or queued installation of app, if any
+func (am *AppManager) CancelInstall(app *AppInfo) {
+	if job, ok := am.queue.ActiveJob(app); ok {
+		am.queue.Cancel(job.ID)
+		return
 	}
+	am.cancelOperation(installOperationKey(app))
+}
 
-	// Clear loading state BEFORE applying filters so UI can display results
-	am.isLoading = false
+// IsInstalling reports whether app is being installed or waits in the queue
+func (am *AppManager) IsInstalling(app *AppInfo) bool {
+	if _, ok := am.queue.ActiveJob(app); ok {
+		return true
+	}
+	return am.isOperationRunning(installOperationKey(app))
+}
 
-	// Apply current source filter to search results
-	am.applyAllFilters()
+// InstallJobs returns the install queue in order, finished jobs included
+func (am *AppManager) InstallJobs() []InstallJob {
+	return am.queue.Jobs()
+}
 
-	am.mutex.Unlock()
+func (am *AppManager) CancelInstallJob(id int) bool {
+	return am.queue.Cancel(id)
+}
 
-	// Clear loading state after everything is done
-	am.SetLoading(false)
+func (am *AppManager) PauseInstallJob(id int) bool {
+	return am.queue.Pause(id)
+}
 
-	return nil
+func (am *AppManager) ResumeInstallJob(id int) bool {
+	return am.queue.Resume(id)
 }
 
-func (am *AppManager) RefreshInstalledApps() error {
-	// Set loading state BEFORE acquiring mutex to avoid deadlock
-	am.SetLoading(true)
+func (am *AppManager) RetryInstallJob(id int) bool {
+	return am.queue.Retry(id)
+}
 
-	am.mutex.Lock()
+// MoveInstallJob moves a job up (negative offset) or down the queue
+func (am *AppManager) MoveInstallJob(id int, offset int) bool {
+	return am.queue.Move(id, offset)
+}
 
-	var allApps []*AppInfo
+func (am *AppManager) ClearFinishedInstallJobs() {
+	am.queue.ClearFinished()
+	am.notifyProgressCallbacks()
+}
 
-	// Exit search mode when refreshing
-	am.isSearchMode = false
+// SetInstallQueuePaused holds every waiting job while paused. Running
+// installs are not interrupted.
+func (am *AppManager) SetInstallQueuePaused(paused bool) {
+	am.queue.SetPaused(paused)
+	am.notifyProgressCallbacks()
+}
 
-	// Get installed apps from winget if available AND enabled
-	if am.wingetManager.IsAvailable() && getWingetEnabled() {
-		apps, err := am.wingetManager.GetInstalledApps()
-		if err == nil {
-			allApps = append(allApps, apps...)
-		}
+func (am *AppManager) IsInstallQueuePaused() bool {
+	return am.queue.IsPaused()
+}
+
+// InstallConcurrency returns how many installs of source run at once
+func (am *AppManager) InstallConcurrency(source string) int {
+	return am.queue.Concurrency(source)
+}
+
+func (am *AppManager) SetInstallConcurrency(source string, limit int) {
+	am.queue.SetConcurrency(source, limit)
+}
+
+// AddProgressCallback registers a callback that runs whenever installation
+// progress changes. Kept apart from AddCallback since it fires far more often.
+func (am *AppManager) AddProgressCallback(callback func()) {
+	if callback != nil {
+		am.progressCallbacks = append(am.progressCallbacks, callback)
 	}
+}
 
-	// Get installed apps from chocolatey if available AND enabled
-	if am.chocoManager.IsAvailable() && getChocoEnabled() {
-		apps, err := am.chocoManager.GetInstalledApps()
-		if err == nil {
-			allApps = append(allApps, apps...)
-		}
+func (am *AppManager) notifyProgressCallbacks() {
+	for _, callback := range am.progressCallbacks {
+		callback()
 	}
+}
 
-	// Mark saved status
-	am.markSavedStatus(allApps)
+func (am *AppManager) resetInstallProgress(key string) {
+	am.opMutex.Lock()
+	am.installProgress[key] = &InstallProgress{Phase: PhaseStarting, Percent: -1}
+	am.opMutex.Unlock()
+	am.notifyProgressCallbacks()
+}
 
-	am.installedApps = allApps
-	am.allApps = allApps // Store all apps for filtering
+func (am *AppManager) recordInstallProgress(key string, event ProgressEvent) {
+	am.opMutex.Lock()
+	progress, ok := am.installProgress[key]
+	if !ok {
+		progress = &InstallProgress{}
+		am.installProgress[key] = progress
+	}
+	progress.apply(event)
+	am.opMutex.Unlock()
+	am.notifyProgressCallbacks()
+}
 
-	// Clear loading state BEFORE applying filters so UI can display results
-	am.isLoading = false
+// GetInstallProgress returns a snapshot of the last installation of app, or
+// nil if it has not been installed during this session
+func (am *AppManager) GetInstallProgress(app *AppInfo) *InstallProgress {
+	snapshot := InstallProgress{Percent: -1}
+	am.opMutex.Lock()
+	progress, ok := am.installProgress[installOperationKey(app)]
+	if ok {
+		snapshot = *progress
+		snapshot.Log = append([]string(nil), progress.Log...)
+	}
+	am.opMutex.Unlock()
 
-	// Apply current source filter to refresh results
-	am.applyAllFilters()
+	// Apps waiting in the queue show their queue state until they start
+	if job, queued := am.queue.ActiveJob(app); queued && job.State != JobRunning {
+		snapshot.Phase = ProgressPhase(job.State)
+		snapshot.Percent = -1
+		return &snapshot
+	}
+	if !ok {
+		return nil
+	}
+	return &snapshot
+}
 
-	am.mutex.Unlock()
+// Shutdown cancels every running operation and waits up to timeout for the
+// package manager processes to exit
+func (am *AppManager) Shutdown(timeout time.Duration) {
+	am.cancel()
 
-	// Clear loading state after everything is done
-	am.SetLoading(false)
+	finished := make(chan struct{})
+	go func() {
+		am.runningOps.Wait()
+		close(finished)
+	}()
 
-	return nil
+	select {
+	case <-finished:
+	case <-time.After(timeout):
+	}
+
+	am.StopRecording()
 }
 
-func (am *AppManager) InstallApp(app *AppInfo) error {
-	var err error
+// StartRecording records every package manager command with its output to a
+// new transcript in the transcripts folder and returns the transcript path
+func (am *AppManager) StartRecording() (string, error) {
+	dataDir, err := getAppDataDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dataDir, "transcripts", fmt.Sprintf("commands_%s.jsonl", time.Now().Format("20060102_150405")))
 
-	switch app.Source {
-	case "winget":
-		if am.wingetManager.IsAvailable() {
-			err = am.wingetManager.Install(app.PackageID)
-		} else {
-			err = fmt.Errorf("winget is not available")
-		}
-	case "chocolatey":
-		if am.chocoManager.IsAvailable() {
-			err = am.chocoManager.Install(app.PackageID)
-		} else {
-			err = fmt.Errorf("chocolatey is not available")
-		}
-	default:
-		err = fmt.Errorf("unknown package source: %s", app.Source)
+	recorder, err := NewRecordingRunner(nil, path)
+	if err != nil {
+		return "", err
 	}
 
-	if err == nil {
-		// Refresh installed apps after successful installation
-		go am.RefreshInstalledApps()
+	am.StopRecording()
+	am.opMutex.Lock()
+	am.recorder = recorder
+	am.opMutex.Unlock()
+	am.backends.SetRunner(recorder)
+	return path, nil
+}
+
+// StopRecording goes back to running commands without recording them
+func (am *AppManager) StopRecording() {
+	am.opMutex.Lock()
+	recorder := am.recorder
+	am.recorder = nil
+	am.opMutex.Unlock()
+
+	if recorder != nil {
+		am.backends.SetRunner(nil)
+		recorder.Close()
 	}
+}
 
-	return err
+// RecordingPath returns the transcript commands are recorded to, or "" when
+// not recording
+func (am *AppManager) RecordingPath() string {
+	am.opMutex.Lock()
+	defer am.opMutex.Unlock()
+	if am.recorder == nil {
+		return ""
+	}
+	return am.recorder.Path()
+}
+
+// IsRecording reports whether commands are being recorded to a transcript
+func (am *AppManager) IsRecording() bool {
+	am.opMutex.Lock()
+	defer am.opMutex.Unlock()
+	return am.recorder != nil
 }
 
 // List management methods
@@ -307,8 +1261,15 @@ func (am *AppManager) SetCurrentList(list *AppList) error {
 	am.currentList = list
 	am.mutex.Unlock()
 
-	// Reload saved apps for the new list
-	return am.LoadSavedApps()
+	// Reload saved apps and their tags for the new list
+	if err := am.LoadSavedApps(); err != nil {
+		return err
+	}
+
+	am.mutex.Lock()
+	am.applyAllFilters()
+	am.mutex.Unlock()
+	return nil
 }
 
 func (am *AppManager) CreateList(name, description string) (*AppList, error) {
@@ -341,20 +1302,57 @@ func (am *AppManager) UpdateList(listID int64, name, description string) error {
 
 	// Update current list if it was the one being modified
 	if am.currentList != nil && am.currentList.ID == listID {
-		for _, list := range am.allLists {
-			if list.ID == listID {
-				am.mutex.Lock()
-				am.currentList = list
-				am.mutex.Unlock()
-				break
-			}
+		am.refreshCurrentList()
+	}
+
+	am.notifyCallbacks()
+	return nil
+}
+
+// DefaultList returns the list flagged as the default, nil before the lists are loaded
+func (am *AppManager) DefaultList() *AppList {
+	am.mutex.RLock()
+	defer am.mutex.RUnlock()
+
+	for _, list := range am.allLists {
+		if list.IsDefault {
+			return list
 		}
 	}
+	return nil
+}
+
+// SetDefaultList makes listID the default list, after which the previous
+// default can be deleted
+func (am *AppManager) SetDefaultList(listID int64) error {
+	err := SetDefaultList(am.db, listID)
+	if err != nil {
+		return err
+	}
+
+	// Reload lists, both the old and the new default changed
+	am.LoadLists()
+	if am.currentList != nil {
+		am.refreshCurrentList()
+	}
 
 	am.notifyCallbacks()
 	return nil
 }
 
+// refreshCurrentList points the current list at its reloaded copy
+func (am *AppManager) refreshCurrentList() {
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	for _, list := range am.allLists {
+		if list.ID == am.currentList.ID {
+			am.currentList = list
+			break
+		}
+	}
+}
+
 func (am *AppManager) DeleteList(listID int64) error {
 	err := DeleteList(am.db, listID)
 	if err != nil {
@@ -366,12 +1364,14 @@ func (am *AppManager) DeleteList(listID int64) error {
 
 	// If the deleted list was current, switch to default
 	if am.currentList != nil && am.currentList.ID == listID {
-		if len(am.allLists) > 0 {
-			am.SetCurrentList(am.allLists[0]) // Switch to default list
+		if list := am.DefaultList(); list != nil {
+			am.SetCurrentList(list)
 		} else {
 			am.mutex.Lock()
 			am.currentList = nil
 			am.savedApps = make([]*AppInfo, 0)
+			am.listTags = nil
+			am.currentTagFilter = "All Tags"
 			am.mutex.Unlock()
 		}
 	}
@@ -388,6 +1388,7 @@ func (am *AppManager) SaveAppToCurrentList(app *AppInfo) error {
 
 	err := SaveAppToList(am.db, am.currentList.ID, app)
 	if err == nil {
+		am.fetchDetailsForSavedApp(app)
 		am.LoadSavedApps()
 
 		// Update current apps to reflect saved status
@@ -403,6 +1404,8 @@ func (am *AppManager) SaveAppToCurrentList(app *AppInfo) error {
 func (am *AppManager) SaveAppToSpecificList(app *AppInfo, listID int64) error {
 	err := SaveAppToList(am.db, listID, app)
 	if err == nil {
+		am.fetchDetailsForSavedApp(app)
+
 		// If it was saved to current list, reload saved apps
 		if am.currentList != nil && am.currentList.ID == listID {
 			am.LoadSavedApps()
@@ -418,12 +1421,12 @@ func (am *AppManager) SaveAppToSpecificList(app *AppInfo, listID int64) error {
 	return err
 }
 
-func (am *AppManager) RemoveAppFromCurrentList(packageID string) error {
+func (am *AppManager) RemoveAppFromCurrentList(source, packageID string) error {
 	if am.currentList == nil {
 		return fmt.Errorf("no list selected")
 	}
 
-	err := RemoveAppFromList(am.db, am.currentList.ID, packageID)
+	err := RemoveAppFromList(am.db, am.currentList.ID, source, packageID)
 	if err == nil {
 		am.LoadSavedApps()
 
@@ -437,8 +1440,8 @@ func (am *AppManager) RemoveAppFromCurrentList(packageID string) error {
 	return err
 }
 
-func (am *AppManager) RemoveAppFromList(packageID string, listID int64) error {
-	err := RemoveAppFromList(am.db, listID, packageID)
+func (am *AppManager) RemoveAppFromList(source, packageID string, listID int64) error {
+	err := RemoveAppFromList(am.db, listID, source, packageID)
 	if err == nil {
 		// If we removed from the current list, reload saved apps
 		if am.currentList != nil && am.currentList.ID == listID {
@@ -455,31 +1458,151 @@ func (am *AppManager) RemoveAppFromList(packageID string, listID int64) error {
 	return err
 }
 
-func (am *AppManager) GetAppListsContaining(packageID string) ([]*AppList, error) {
-	return GetAppListsContaining(am.db, packageID)
+// GetAppDetails returns the package details of app, asking its backend the
+// first time. Details of saved apps are persisted so they survive restarts.
+func (am *AppManager) GetAppDetails(ctx context.Context, app *AppInfo) (*PackageDetails, error) {
+	if app.Details != nil {
+		return app.Details, nil
+	}
+
+	key := app.Source + "|" + app.PackageID
+	am.opMutex.Lock()
+	details, ok := am.detailsCache[key]
+	am.opMutex.Unlock()
+
+	if !ok {
+		backend, err := am.backendFor(ctx, app.Source)
+		if err != nil {
+			return nil, err
+		}
+		details, err = backend.Details(ctx, app.PackageID)
+		if err != nil {
+			return nil, err
+		}
+
+		am.opMutex.Lock()
+		am.detailsCache[key] = details
+		am.opMutex.Unlock()
+	}
+
+	app.Details = details
+	if app.Description == "" {
+		app.Description = details.Description
+	}
+
+	if saved, err := GetAppListsContaining(am.db, app.Source, app.PackageID); err == nil && len(saved) > 0 {
+		if err := UpdateSavedAppDetails(am.db, app.Source, app.PackageID, details); err != nil {
+			return details, err
+		}
+	}
+
+	return details, nil
+}
+
+// fetchDetailsForSavedApp looks up the details of a newly saved app in the
+// background so the saved copy carries them
+func (am *AppManager) fetchDetailsForSavedApp(app *AppInfo) {
+	if app.Details != nil {
+		return
+	}
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				// Handle panic gracefully
+			}
+		}()
+		am.GetAppDetails(am.ctx, app)
+	}()
+}
+
+func (am *AppManager) GetAppListsContaining(source, packageID string) ([]*AppList, error) {
+	return GetAppListsContaining(am.db, source, packageID)
 }
 
-func (am *AppManager) InstallAllAppsInList(listID int64) error {
+// InstallAllAppsInList installs every app saved to the list and reports what
+// happened to each of them
+func (am *AppManager) InstallAllAppsInList(ctx context.Context, listID int64, options BatchOptions) (*BatchReport, error) {
 	apps, err := GetAppsInList(am.db, listID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	for _, app := range apps {
-		err := am.InstallApp(app)
-		if err != nil {
-			return fmt.Errorf("failed to install %s: %v", app.Name, err)
+	am.mutex.Lock()
+	am.markInstalledStatus(apps)
+	listName := ""
+	for _, list := range am.allLists {
+		if list.ID == listID {
+			listName = list.Name
 		}
 	}
+	am.mutex.Unlock()
 
-	return nil
+	report := am.InstallApps(ctx, apps, options)
+	report.ListName = listName
+	return report, nil
 }
 
-func (am *AppManager) InstallAllAppsInCurrentList() error {
+func (am *AppManager) InstallAllAppsInCurrentList(ctx context.Context, options BatchOptions) (*BatchReport, error) {
 	if am.currentList == nil {
-		return fmt.Errorf("no list selected")
+		return nil, fmt.Errorf("no list selected")
 	}
-	return am.InstallAllAppsInList(am.currentList.ID)
+	return am.InstallAllAppsInList(ctx, am.currentList.ID, options)
+}
+
+// InstallApps installs apps through the install queue. Failures are collected
+// in the report instead of ending the batch, unless options.StopOnFailure is set.
+// Apps keep their required version and pin from the list, and apps that need
+// a restart are collected in PendingReboots.
+func (am *AppManager) InstallApps(ctx context.Context, apps []*AppInfo, options BatchOptions) *BatchReport {
+	ctx, done := am.startOperation(ctx, "batch")
+	defer done()
+	return runBatch(ctx, am.queue, apps, options)
+}
+
+// CancelBatch stops the running batch install, if any. Its running installs
+// are cancelled and the apps still waiting are skipped.
+func (am *AppManager) CancelBatch() {
+	am.cancelOperation("batch")
+}
+
+// IsBatchRunning reports whether a batch install is in progress
+func (am *AppManager) IsBatchRunning() bool {
+	return am.isOperationRunning("batch")
+}
+
+// ExportBatchReport writes report to the exports folder as "csv" or "json"
+// and returns the path of the file
+func (am *AppManager) ExportBatchReport(report *BatchReport, format string) (string, error) {
+	appDataDir, err := getAppDataDir()
+	if err != nil {
+		return "", err
+	}
+	exportDir := filepath.Join(appDataDir, "exports")
+	if err := os.MkdirAll(exportDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create exports directory: %w", err)
+	}
+
+	filename := fmt.Sprintf("install_report_%s.%s", report.StartedAt.Format("20060102_150405"), format)
+	path := filepath.Join(exportDir, filename)
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create report file: %w", err)
+	}
+	defer file.Close()
+
+	switch format {
+	case "csv":
+		err = report.WriteCSV(file)
+	case "json":
+		err = report.WriteJSON(file)
+	default:
+		err = fmt.Errorf("unknown report format %q", format)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write report: %w", err)
+	}
+	return path, nil
 }
 
 // Modified existing methods to work with current list
@@ -487,6 +1610,8 @@ func (am *AppManager) LoadSavedApps() error {
 	if am.currentList == nil {
 		am.mutex.Lock()
 		am.savedApps = make([]*AppInfo, 0)
+		am.listTags = nil
+		am.currentTagFilter = "All Tags"
 		am.mutex.Unlock()
 		return nil
 	}
@@ -495,43 +1620,111 @@ func (am *AppManager) LoadSavedApps() error {
 	if err != nil {
 		return err
 	}
+	tags, err := GetAllTags(am.db)
+	if err != nil {
+		return err
+	}
+	listTags, err := GetTagsInList(am.db, am.currentList.ID)
+	if err != nil {
+		return err
+	}
 
 	am.mutex.Lock()
+	am.markInstalledStatus(apps)
 	am.savedApps = apps
+	am.allTags = tags
+	am.listTags = listTags
+	// A tag no app in this list has would hide every app
+	if !hasTag(listTags, am.currentTagFilter) {
+		am.currentTagFilter = "All Tags"
+	}
 	am.mutex.Unlock()
 
 	return nil
 }
 
+// markInstalledStatus copies the installed state and version of installed
+// apps onto saved ones. This method assumes the mutex is already locked.
+func (am *AppManager) markInstalledStatus(apps []*AppInfo) {
+	installedMap := make(map[string]*AppInfo)
+	for _, installed := range am.installedApps {
+		installedMap[installed.Source+"|"+installed.PackageID] = installed
+	}
+	availableMap := make(map[string]string)
+	for _, upgradable := range am.upgradableApps {
+		availableMap[upgradable.Source+"|"+upgradable.PackageID] = upgradable.AvailableVersion
+	}
+
+	for _, app := range apps {
+		key := app.Source + "|" + app.PackageID
+		if installed, ok := installedMap[key]; ok {
+			app.IsInstalled = true
+			app.InstalledVersion = installed.InstalledVersion
+			app.AvailableVersion = installed.AvailableVersion
+			if app.AvailableVersion == "" {
+				app.AvailableVersion = availableMap[key]
+			}
+		} else {
+			// An uninstalled app has nothing to upgrade
+			app.IsInstalled = false
+			app.InstalledVersion = ""
+			app.AvailableVersion = ""
+		}
+	}
+}
+
 func (am *AppManager) markSavedStatus(apps []*AppInfo) {
 	if am.currentList == nil {
 		return
 	}
 
-	savedMap := make(map[string]bool)
+	savedMap := make(map[string]*AppInfo)
 	for _, saved := range am.savedApps {
-		savedMap[saved.PackageID] = true
+		savedMap[saved.Source+"|"+saved.PackageID] = saved
 	}
 
 	for _, app := range apps {
-		app.IsSaved = savedMap[app.PackageID]
+		saved, ok := savedMap[app.Source+"|"+app.PackageID]
+		app.IsSaved = ok
 		if app.IsSaved {
 			app.ListID = am.currentList.ID
+			app.Tags = saved.Tags
+		} else {
+			app.Tags = nil
 		}
 	}
 }
 
+// markUpgradeStatus copies the available version of pending upgrades onto the given apps
+func (am *AppManager) markUpgradeStatus(apps []*AppInfo) {
+	availableMap := make(map[string]string)
+	for _, upgradable := range am.upgradableApps {
+		availableMap[upgradable.Source+"|"+upgradable.PackageID] = upgradable.AvailableVersion
+	}
+
+	for _, app := range apps {
+		app.AvailableVersion = availableMap[app.Source+"|"+app.PackageID]
+	}
+}
+
 // Legacy methods for backward compatibility
 func (am *AppManager) SaveApp(app *AppInfo) error {
 	return am.SaveAppToCurrentList(app)
 }
 
-func (am *AppManager) RemoveSavedApp(packageID string) error {
-	return am.RemoveAppFromCurrentList(packageID)
+func (am *AppManager) RemoveSavedApp(source, packageID string) error {
+	return am.RemoveAppFromCurrentList(source, packageID)
 }
 
-func (am *AppManager) InstallAllSavedApps() error {
-	return am.InstallAllAppsInCurrentList()
+func (am *AppManager) InstallAllSavedApps(ctx context.Context) error {
+	report, err := am.InstallAllAppsInCurrentList(ctx, BatchOptions{StopOnFailure: true})
+	if err != nil {
+		return err
+	}
+	if failed := report.FailedApps(); len(failed) > 0 {
+		return fmt.Errorf("failed to install %s", failed[0].Name)
+	}
+	return nil
 }
 
 func (am *AppManager) GetCurrentApps() []*AppInfo {
@@ -571,6 +1764,15 @@ func (am *AppManager) GetInstalledApps() []*AppInfo {
 	return result
 }
 
+func (am *AppManager) GetUpgradableApps() []*AppInfo {
+	am.mutex.RLock()
+	defer am.mutex.RUnlock()
+
+	result := make([]*AppInfo, len(am.upgradableApps))
+	copy(result, am.upgradableApps)
+	return result
+}
+
 func (am *AppManager) SetViewFilter(viewFilter string) {
 	am.mutex.Lock()
 	defer am.mutex.Unlock()
@@ -608,6 +1810,9 @@ func (am *AppManager) applyAllFilters() {
 		case "Saved Apps":
 			baseApps = make([]*AppInfo, len(am.savedApps))
 			copy(baseApps, am.savedApps)
+		case "Updates Available":
+			baseApps = make([]*AppInfo, len(am.upgradableApps))
+			copy(baseApps, am.upgradableApps)
 		default: // "All Results"
 			// In "All Results" view without search, show combined installed + saved apps
 			combinedApps := make([]*AppInfo, 0)
@@ -618,11 +1823,11 @@ func (am *AppManager) applyAllFilters() {
 			// Add saved apps that aren't already installed (avoid duplicates)
 			installedMap := make(map[string]bool)
 			for _, installed := range am.installedApps {
-				installedMap[installed.PackageID] = true
+				installedMap[installed.Source+"|"+installed.PackageID] = true
 			}
 
 			for _, saved := range am.savedApps {
-				if !installedMap[saved.PackageID] {
+				if !installedMap[saved.Source+"|"+saved.PackageID] {
 					combinedApps = append(combinedApps, saved)
 				}
 			}
@@ -644,6 +1849,21 @@ func (am *AppManager) applyAllFilters() {
 		am.currentApps = filteredApps
 	}
 
+	// Finally keep only apps whose saved copy in the current list has the tag
+	if am.currentTagFilter != "All Tags" {
+		savedTags := make(map[string][]string)
+		for _, saved := range am.savedApps {
+			savedTags[saved.Source+"|"+saved.PackageID] = saved.Tags
+		}
+		var taggedApps []*AppInfo
+		for _, app := range am.currentApps {
+			if hasTag(savedTags[app.Source+"|"+app.PackageID], am.currentTagFilter) {
+				taggedApps = append(taggedApps, app)
+			}
+		}
+		am.currentApps = taggedApps
+	}
+
 	am.notifyCallbacks()
 }
 
@@ -659,6 +1879,57 @@ func (am *AppManager) GetCurrentSourceFilter() string {
 	return am.currentSourceFilter
 }
 
+// FilterByTag shows only apps with tag, or every app for "All Tags"
+func (am *AppManager) FilterByTag(tag string) {
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	am.currentTagFilter = tag
+	am.applyAllFilters()
+}
+
+func (am *AppManager) GetCurrentTagFilter() string {
+	am.mutex.RLock()
+	defer am.mutex.RUnlock()
+	return am.currentTagFilter
+}
+
+// GetTags returns every tag in use by a saved app, in any list
+func (am *AppManager) GetTags() []string {
+	am.mutex.RLock()
+	defer am.mutex.RUnlock()
+
+	result := make([]string, len(am.allTags))
+	copy(result, am.allTags)
+	return result
+}
+
+// GetCurrentListTags returns the tags in use by a saved app in the current
+// list, the ones the tag filter can match
+func (am *AppManager) GetCurrentListTags() []string {
+	am.mutex.RLock()
+	defer am.mutex.RUnlock()
+
+	result := make([]string, len(am.listTags))
+	copy(result, am.listTags)
+	return result
+}
+
+// SetAppTags replaces the tags of a saved app in its list
+func (am *AppManager) SetAppTags(app *AppInfo, tags []string) error {
+	if err := SetSavedAppTags(am.db, app.ListID, app.Source, app.PackageID, tags); err != nil {
+		return err
+	}
+	app.Tags = normalizeTags(tags)
+
+	am.LoadSavedApps()
+	am.mutex.Lock()
+	am.markSavedStatus(am.currentApps)
+	am.applyAllFilters()
+	am.mutex.Unlock()
+	return nil
+}
+
 func (am *AppManager) GetCurrentViewFilter() string {
 	am.mutex.RLock()
 	defer am.mutex.RUnlock()
@@ -753,6 +2024,7 @@ func (am *AppManager) ExportListToCSV(listID int64) error {
 		"Is Installed",
 		"Is Saved",
 		"List ID",
+		"Tags",
 	})
 	if err != nil {
 		return err
@@ -773,6 +2045,7 @@ func (am *AppManager) ExportListToCSV(listID int64) error {
 			strconv.FormatBool(app.IsInstalled),
 			strconv.FormatBool(app.IsSaved),
 			strconv.FormatInt(app.ListID, 10),
+			formatTags(app.Tags),
 		})
 		if err != nil {
 			return err
@@ -822,6 +2095,14 @@ func (am *AppManager) ImportListFromCSV(filepath string) (*AppList, int, error)
 		return nil, 0, fmt.Errorf("invalid CSV format: expected at least 5 columns, got %d", len(header))
 	}
 
+	// Exports before tags were added have no Tags column
+	tagsColumn := -1
+	for i, column := range header {
+		if strings.EqualFold(strings.TrimSpace(column), "Tags") {
+			tagsColumn = i
+		}
+	}
+
 	// Read all records
 	records, err := reader.ReadAll()
 	if err != nil {
@@ -898,6 +2179,9 @@ func (am *AppManager) ImportListFromCSV(filepath string) (*AppList, int, error)
 			IsSaved:     true,
 			ListID:      targetList.ID,
 		}
+		if tagsColumn >= 0 && tagsColumn < len(record) {
+			app.Tags = parseTags(record[tagsColumn])
+		}
 
 		// Skip empty records
 		if app.Name == "" || app.PackageID == "" {
@@ -905,7 +2189,7 @@ func (am *AppManager) ImportListFromCSV(filepath string) (*AppList, int, error)
 		}
 
 		// Check if app already exists in this list
-		exists, err := IsAppInList(am.db, targetList.ID, app.PackageID)
+		exists, err := IsAppInList(am.db, targetList.ID, app.Source, app.PackageID)
 		if err != nil {
 			return targetList, importedCount, fmt.Errorf("error checking if app exists (row %d): %v", i+2, err)
 		}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BackendRegistry holds the package managers known to the installer, keyed by
+// their source name and kept in registration order
+type BackendRegistry struct {
+	mutex    sync.RWMutex
+	order    []string
+	backends map[string]PackageManager
+	enabled  map[string]bool
+	status   map[string]*BackendStatus // Last detection result, missing until detected
+	paths    map[string]string         // Executable overrides set in settings
+}
+
+// BackendStatus is what detection found out about an installed backend
+type BackendStatus struct {
+	Available  bool
+	Version    string
+	Path       string // Resolved executable, or the configured one if it could not be resolved
+	Error      string // Why the backend is not available
+	DetectedAt time.Time
+}
+
+func NewBackendRegistry() *BackendRegistry {
+	return &BackendRegistry{
+		order:    make([]string, 0),
+		backends: make(map[string]PackageManager),
+		enabled:  make(map[string]bool),
+		status:   make(map[string]*BackendStatus),
+		paths:    make(map[string]string),
+	}
+}
+
+// newDefaultBackendRegistry registers every package manager shipped with the installer
+func newDefaultBackendRegistry() *BackendRegistry {
+	registry := NewBackendRegistry()
+	registry.Register(&WingetManager{})
+	registry.Register(&ChocolateyManager{})
+	registry.Register(&ScoopManager{})
+	return registry
+}
+
+// Register adds a package manager, enabled by default. Registering the same
+// source name twice replaces the previous backend but keeps its position.
+func (r *BackendRegistry) Register(backend PackageManager) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	name := backend.Name()
+	if _, exists := r.backends[name]; !exists {
+		r.order = append(r.order, name)
+		r.enabled[name] = true
+	}
+	r.backends[name] = backend
+	delete(r.status, name)
+}
+
+func (r *BackendRegistry) Get(source string) (PackageManager, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	backend, ok := r.backends[source]
+	return backend, ok
+}
+
+// All returns every registered backend, enabled or not
+func (r *BackendRegistry) All() []PackageManager {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]PackageManager, 0, len(r.order))
+	for _, name := range r.order {
+		result = append(result, r.backends[name])
+	}
+	return result
+}
+
+// Enabled returns the backends the user has not switched off in settings
+func (r *BackendRegistry) Enabled() []PackageManager {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]PackageManager, 0, len(r.order))
+	for _, name := range r.order {
+		if r.enabled[name] {
+			result = append(result, r.backends[name])
+		}
+	}
+	return result
+}
+
+// Active returns the enabled backends that are installed on this machine
+func (r *BackendRegistry) Active(ctx context.Context) []PackageManager {
+	var result []PackageManager
+	for _, backend := range r.Enabled() {
+		if r.IsAvailable(ctx, backend.Name()) {
+			result = append(result, backend)
+		}
+	}
+	return result
+}
+
+// IsAvailable reports whether a backend is installed. Backends are only
+// detected once; call Detect to look again.
+func (r *BackendRegistry) IsAvailable(ctx context.Context, source string) bool {
+	status, ok := r.Status(source)
+	if !ok {
+		status = r.DetectBackend(ctx, source)
+	}
+	return status.Available
+}
+
+// Status returns the last detection result of a backend. ok is false if it
+// has not been detected yet.
+func (r *BackendRegistry) Status(source string) (status BackendStatus, ok bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if detected, found := r.status[source]; found {
+		return *detected, true
+	}
+	return BackendStatus{}, false
+}
+
+// Detect looks for every registered backend at once and records their
+// versions and executable paths
+func (r *BackendRegistry) Detect(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, backend := range r.All() {
+		wg.Add(1)
+		go func(source string) {
+			defer wg.Done()
+			r.DetectBackend(ctx, source)
+		}(backend.Name())
+	}
+	wg.Wait()
+}
+
+// DetectBackend runs the version command of a backend and records the result
+func (r *BackendRegistry) DetectBackend(ctx context.Context, source string) BackendStatus {
+	backend, ok := r.Get(source)
+	if !ok {
+		return BackendStatus{Error: fmt.Sprintf("unknown package source: %s", source)}
+	}
+
+	executable := backend.Executable()
+	status := BackendStatus{Path: executable, DetectedAt: time.Now()}
+	if path, err := exec.LookPath(executable); err == nil {
+		status.Path = path
+	}
+
+	version, err := backend.Version(ctx)
+	if err != nil {
+		status.Error = err.Error()
+	} else {
+		status.Available = true
+		status.Version = version
+	}
+
+	// A cancelled detection says nothing about the backend, and neither does
+	// one of an executable that was replaced in the meantime
+	if ctx.Err() == nil && backend.Executable() == executable {
+		r.mutex.Lock()
+		r.status[source] = &status
+		r.mutex.Unlock()
+	}
+	return status
+}
+
+// SetExecutablePath makes a backend run the program at path instead of the
+// one found on PATH. An empty path restores the default. The backend is
+// detected again the next time it is used.
+func (r *BackendRegistry) SetExecutablePath(source, path string) error {
+	backend, ok := r.Get(source)
+	if !ok {
+		return fmt.Errorf("unknown package source: %s", source)
+	}
+
+	path = strings.TrimSpace(path)
+	backend.SetExecutable(path)
+
+	r.mutex.Lock()
+	if path == "" {
+		delete(r.paths, source)
+	} else {
+		r.paths[source] = path
+	}
+	delete(r.status, source)
+	r.mutex.Unlock()
+	return nil
+}
+
+// ExecutablePath returns the executable override of a backend, empty when it
+// runs the default program found on PATH
+func (r *BackendRegistry) ExecutablePath(source string) string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.paths[source]
+}
+
+func (r *BackendRegistry) IsEnabled(source string) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.enabled[source]
+}
+
+// SetEnabled switches a backend on or off. At least one backend must stay enabled.
+func (r *BackendRegistry) SetEnabled(source string, enabled bool) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.backends[source]; !ok {
+		return fmt.Errorf("unknown package source: %s", source)
+	}
+
+	if !enabled && r.enabled[source] {
+		enabledCount := 0
+		for _, name := range r.order {
+			if r.enabled[name] {
+				enabledCount++
+			}
+		}
+		if enabledCount <= 1 {
+			return fmt.Errorf("at least one package manager must be enabled")
+		}
+	}
+
+	r.enabled[source] = enabled
+	return nil
+}
+
+// SetRunner makes every registered backend run its commands through runner.
+// A nil runner runs the real programs again.
+func (r *BackendRegistry) SetRunner(runner CommandRunner) {
+	for _, backend := range r.All() {
+		backend.SetRunner(runner)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fakeBackend is a package manager that only answers version checks
+type fakeBackend struct {
+	PackageManager // Nil, calling anything else panics
+
+	name         string
+	executable   string
+	installed    bool
+	versionCalls int
+	onVersion    func() // Called while the version is checked
+}
+
+func (f *fakeBackend) Name() string        { return f.name }
+func (f *fakeBackend) DisplayName() string { return f.name }
+
+func (f *fakeBackend) Executable() string {
+	if f.executable != "" {
+		return f.executable
+	}
+	return f.name
+}
+
+func (f *fakeBackend) SetExecutable(path string) { f.executable = path }
+
+func (f *fakeBackend) Version(ctx context.Context) (string, error) {
+	f.versionCalls++
+	if f.onVersion != nil {
+		f.onVersion()
+	}
+	if !f.installed {
+		return "", errors.New("executable file not found")
+	}
+	return "1.2.3", nil
+}
+
+func TestBackendRegistryDetection(t *testing.T) {
+	installed := &fakeBackend{name: "fake-installed", installed: true}
+	missing := &fakeBackend{name: "fake-missing"}
+
+	registry := NewBackendRegistry()
+	registry.Register(installed)
+	registry.Register(missing)
+
+	ctx := context.Background()
+	if _, ok := registry.Status(installed.Name()); ok {
+		t.Fatal("backend has a status before detection")
+	}
+
+	// Availability is detected once and then served from the registry
+	for i := 0; i < 3; i++ {
+		if !registry.IsAvailable(ctx, installed.Name()) {
+			t.Fatal("installed backend reported unavailable")
+		}
+		if registry.IsAvailable(ctx, missing.Name()) {
+			t.Fatal("missing backend reported available")
+		}
+	}
+	if installed.versionCalls != 1 || missing.versionCalls != 1 {
+		t.Errorf("version checked %d and %d times, want once each", installed.versionCalls, missing.versionCalls)
+	}
+
+	status, ok := registry.Status(installed.Name())
+	if !ok || status.Version != "1.2.3" || status.Error != "" {
+		t.Errorf("installed status = %+v", status)
+	}
+	status, _ = registry.Status(missing.Name())
+	if status.Available || status.Error == "" {
+		t.Errorf("missing status = %+v", status)
+	}
+	if active := registry.Active(ctx); len(active) != 1 || active[0] != installed {
+		t.Errorf("Active returned %d backends, want only the installed one", len(active))
+	}
+
+	// Detect looks again
+	registry.Detect(ctx)
+	if installed.versionCalls != 2 || missing.versionCalls != 2 {
+		t.Errorf("Detect did not check every backend again")
+	}
+
+	// An executable override forgets the old result
+	if err := registry.SetExecutablePath(missing.Name(), `  C:\Tools\fake.exe `); err != nil {
+		t.Fatal(err)
+	}
+	if missing.Executable() != `C:\Tools\fake.exe` || registry.ExecutablePath(missing.Name()) != `C:\Tools\fake.exe` {
+		t.Errorf("override not applied, executable is %q", missing.Executable())
+	}
+	if _, ok := registry.Status(missing.Name()); ok {
+		t.Error("status kept after changing the executable")
+	}
+	if err := registry.SetExecutablePath("unknown", "x"); err == nil {
+		t.Error("expected an error for an unknown backend")
+	}
+}
+
+func TestBackendRegistryDetectionRacingOverride(t *testing.T) {
+	backend := &fakeBackend{name: "fake"}
+	registry := NewBackendRegistry()
+	registry.Register(backend)
+
+	// The override arrives while the default executable is still being checked
+	backend.onVersion = func() {
+		backend.onVersion = nil
+		if err := registry.SetExecutablePath(backend.Name(), `C:\Toolsake.exe`); err != nil {
+			t.Fatal(err)
+		}
+		backend.installed = true
+	}
+	registry.DetectBackend(context.Background(), backend.Name())
+	if status, ok := registry.Status(backend.Name()); ok {
+		t.Errorf("stale status %+v was kept for the new executable", status)
+	}
+
+	// The next check detects the override
+	if !registry.IsAvailable(context.Background(), backend.Name()) {
+		t.Error("the overridden executable was not detected")
+	}
+}
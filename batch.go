@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// BatchOutcome is what happened to one app of a batch install
+type BatchOutcome string
+
+const (
+	BatchInstalled        BatchOutcome = "Installed"
+	BatchAlreadyInstalled BatchOutcome = "Already installed"
+	BatchRestartRequired  BatchOutcome = "Restart required"
+	BatchSkipped          BatchOutcome = "Skipped"
+	BatchFailed           BatchOutcome = "Failed"
+)
+
+// batchOutcomes lists the outcomes in the order summaries show them
+var batchOutcomes = []BatchOutcome{BatchInstalled, BatchAlreadyInstalled, BatchRestartRequired, BatchSkipped, BatchFailed}
+
+// BatchOptions adjust how a batch install handles failures
+type BatchOptions struct {
+	StopOnFailure bool // Skip the remaining apps after the first failure
+}
+
+// BatchResult is the outcome of one app in a batch install
+type BatchResult struct {
+	Name      string       `json:"name"`
+	PackageID string       `json:"package_id"`
+	Source    string       `json:"source"`
+	Version   string       `json:"version"`
+	Outcome   BatchOutcome `json:"outcome"`
+	Reason    string       `json:"reason,omitempty"` // Why the app failed or was skipped
+
+	app *AppInfo // Kept to retry failed apps and prompt for restarts
+}
+
+// BatchReport collects the outcome of every app in a batch install
+type BatchReport struct {
+	ListName   string        `json:"list_name"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt time.Time     `json:"finished_at"`
+	Results    []BatchResult `json:"results"`
+}
+
+// batchOutcomeFor classifies the error InstallApp returned. Cancelled
+// installs count as skipped rather than failed so they are not retried.
+func batchOutcomeFor(err error) BatchOutcome {
+	switch {
+	case err == nil:
+		return BatchInstalled
+	case errors.Is(err, context.Canceled):
+		return BatchSkipped
+	case errors.Is(err, ErrRebootRequired):
+		return BatchRestartRequired
+	case errors.Is(err, ErrAlreadyInstalled):
+		return BatchAlreadyInstalled
+	}
+	return BatchFailed
+}
+
+// runBatch queues every app that needs installing and reports how each one
+// ended. Cancelling ctx stops the installs that are still running and skips
+// the waiting ones.
+func runBatch(ctx context.Context, queue *InstallQueue, apps []*AppInfo, options BatchOptions) *BatchReport {
+	report := &BatchReport{StartedAt: time.Now()}
+
+	// Everything is queued up front so other backends can run in parallel.
+	// Apps that were already queued, e.g. from their row, keep their job and
+	// are not owned by the batch, so stopping the batch leaves them alone.
+	jobIDs := make([]int, len(apps))
+	owned := make(map[int]bool)
+	for i, app := range apps {
+		if app.IsInstalled && !versionMismatch(app) {
+			continue
+		}
+		id, created := queue.enqueue(app)
+		jobIDs[i] = id
+		if created {
+			owned[id] = true
+		}
+	}
+
+	// stop skips every app that has not started yet, all at once so none of
+	// them takes the slot of an install that just ended
+	stopReason := ""
+	skipped := make(map[int]bool)
+	stop := func(reason string, from int) {
+		stopReason = reason
+		for _, id := range jobIDs[from:] {
+			if owned[id] && queue.cancelWaiting(id) {
+				skipped[id] = true
+			}
+		}
+	}
+
+	for i, app := range apps {
+		if jobIDs[i] == 0 {
+			report.add(app, BatchAlreadyInstalled, "")
+			continue
+		}
+		// Once stopped the batch no longer waits for jobs it does not own
+		if skipped[jobIDs[i]] || (stopReason != "" && (!owned[jobIDs[i]] || queue.cancelWaiting(jobIDs[i]))) {
+			report.add(app, BatchSkipped, stopReason)
+			continue
+		}
+
+		err := queue.Wait(ctx, jobIDs[i])
+		if ctx.Err() != nil {
+			if stopReason == "" {
+				stop("the batch was cancelled", i+1)
+			}
+			// Installs already running on other backends are stopped too, and
+			// waited for so the report shows how they really ended
+			if owned[jobIDs[i]] {
+				queue.Cancel(jobIDs[i])
+				err = queue.Wait(context.Background(), jobIDs[i])
+			}
+		}
+
+		outcome := batchOutcomeFor(err)
+		reason := ""
+		switch outcome {
+		case BatchSkipped:
+			reason = "cancelled"
+		case BatchFailed:
+			reason = err.Error()
+			if hint := ErrorHint(err); hint != "" {
+				reason += " (" + hint + ")"
+			}
+			if options.StopOnFailure && stopReason == "" {
+				stop(fmt.Sprintf("stopped after %s failed", app.Name), i+1)
+			}
+		}
+		report.add(app, outcome, reason)
+	}
+
+	report.FinishedAt = time.Now()
+	return report
+}
+
+func (r *BatchReport) add(app *AppInfo, outcome BatchOutcome, reason string) {
+	version := requiredVersion(app)
+	if version == "" {
+		version = "latest"
+	}
+	r.Results = append(r.Results, BatchResult{
+		Name:      app.Name,
+		PackageID: app.PackageID,
+		Source:    app.Source,
+		Version:   version,
+		Outcome:   outcome,
+		Reason:    reason,
+		app:       app,
+	})
+}
+
+// Count returns how many apps ended with outcome
+func (r *BatchReport) Count(outcome BatchOutcome) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Outcome == outcome {
+			count++
+		}
+	}
+	return count
+}
+
+// FailedApps returns the apps to install again with "Retry Failed"
+func (r *BatchReport) FailedApps() []*AppInfo {
+	return r.apps(BatchFailed)
+}
+
+// RestartRequiredApps returns the apps of the batch that wait for a restart
+func (r *BatchReport) RestartRequiredApps() []*AppInfo {
+	return r.apps(BatchRestartRequired)
+}
+
+func (r *BatchReport) apps(outcome BatchOutcome) []*AppInfo {
+	var apps []*AppInfo
+	for _, result := range r.Results {
+		if result.Outcome == outcome && result.app != nil {
+			apps = append(apps, result.app)
+		}
+	}
+	return apps
+}
+
+// Summary counts the outcomes, e.g. "12 installed, 1 failed"
+func (r *BatchReport) Summary() string {
+	var parts []string
+	for _, outcome := range batchOutcomes {
+		if count := r.Count(outcome); count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count, strings.ToLower(string(outcome))))
+		}
+	}
+	if len(parts) == 0 {
+		return "No applications to install"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (r *BatchReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"Name", "Package ID", "Source", "Version", "Outcome", "Reason"}); err != nil {
+		return err
+	}
+	for _, result := range r.Results {
+		err := writer.Write([]string{result.Name, result.PackageID, result.Source, result.Version, string(result.Outcome), result.Reason})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (r *BatchReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testBatchReport() *BatchReport {
+	report := &BatchReport{
+		ListName:   "Workstation",
+		StartedAt:  time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC),
+		FinishedAt: time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC),
+	}
+	git := &AppInfo{Name: "Git", PackageID: "Git.Git", Source: "winget"}
+	node := &AppInfo{Name: "Node.js", PackageID: "nodejs", Source: "choco", RequiredVersion: "20.11.0"}
+	report.add(git, BatchInstalled, "")
+	report.add(&AppInfo{Name: "7-Zip", PackageID: "7zip.7zip", Source: "winget"}, BatchAlreadyInstalled, "")
+	report.add(node, BatchFailed, "choco install of nodejs failed: exit status 1")
+	report.add(&AppInfo{Name: "Vim", PackageID: "main/vim", Source: "scoop"}, BatchSkipped, "stopped after the first failure")
+	return report
+}
+
+func TestBatchOutcomeFor(t *testing.T) {
+	tests := []struct {
+		err  error
+		want BatchOutcome
+	}{
+		{nil, BatchInstalled},
+		{&PackageError{Kind: ErrAlreadyInstalled, Err: &replayExitError{code: 1}}, BatchAlreadyInstalled},
+		{fmt.Errorf("install: %w", ErrRebootRequired), BatchRestartRequired},
+		{&PackageError{Kind: ErrHashMismatch, Err: &replayExitError{code: 1}}, BatchFailed},
+		{errors.New("winget is not available"), BatchFailed},
+		{fmt.Errorf("installation of Git was cancelled: %w", context.Canceled), BatchSkipped},
+	}
+	for _, tt := range tests {
+		if got := batchOutcomeFor(tt.err); got != tt.want {
+			t.Errorf("batchOutcomeFor(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRunBatchContinuesPastFailures(t *testing.T) {
+	installer := newBlockingInstaller()
+	queue := NewInstallQueue(context.Background(), installer.install)
+
+	apps := []*AppInfo{
+		{Name: "Git", PackageID: "Git.Git", Source: "winget"},
+		{Name: "7-Zip", PackageID: "7zip.7zip", Source: "winget"},
+		{Name: "Node.js", PackageID: "nodejs", Source: "choco"},
+		{Name: "Vim", PackageID: "vim", Source: "scoop", IsInstalled: true},
+	}
+	reports := make(chan *BatchReport)
+	go func() { reports <- runBatch(context.Background(), queue, apps, BatchOptions{}) }()
+
+	installer.expectStarted(t, "Git.Git", "nodejs")
+	installer.finish("Git.Git", errors.New("exit status 1"))
+	installer.expectStarted(t, "7zip.7zip")
+	installer.finish("7zip.7zip", nil)
+	installer.finish("nodejs", &PackageError{Kind: ErrRebootRequired, Err: &replayExitError{code: 3010}})
+
+	report := <-reports
+	want := []BatchOutcome{BatchFailed, BatchInstalled, BatchRestartRequired, BatchAlreadyInstalled}
+	for i, result := range report.Results {
+		if result.Outcome != want[i] {
+			t.Errorf("%s: outcome %s, want %s", result.Name, result.Outcome, want[i])
+		}
+	}
+	if failed := report.FailedApps(); len(failed) != 1 || failed[0].PackageID != "Git.Git" {
+		t.Errorf("FailedApps = %v, want only Git", failed)
+	}
+	if restart := report.RestartRequiredApps(); len(restart) != 1 || restart[0].PackageID != "nodejs" {
+		t.Errorf("RestartRequiredApps = %v, want only Node.js", restart)
+	}
+}
+
+func TestRunBatchStopOnFailure(t *testing.T) {
+	installer := newBlockingInstaller()
+	queue := NewInstallQueue(context.Background(), installer.install)
+
+	apps := []*AppInfo{
+		{Name: "Git", PackageID: "Git.Git", Source: "winget"},
+		{Name: "Node.js", PackageID: "nodejs", Source: "choco"},
+		{Name: "7-Zip", PackageID: "7zip.7zip", Source: "winget"},
+		{Name: "VS Code", PackageID: "vscode", Source: "winget"},
+	}
+	reports := make(chan *BatchReport)
+	go func() { reports <- runBatch(context.Background(), queue, apps, BatchOptions{StopOnFailure: true}) }()
+
+	// Cancelling Git from the queue panel skips it without stopping the batch
+	installer.expectStarted(t, "Git.Git", "nodejs")
+	jobs := queue.Jobs()
+	queue.Cancel(jobs[0].ID)
+	installer.expectStarted(t, "7zip.7zip")
+
+	// The first real failure skips the apps that have not started, 7-Zip
+	// is already running and finishes
+	installer.finish("nodejs", errors.New("exit status 1"))
+	deadline := time.Now().Add(2 * time.Second)
+	for queueStates(queue)["vscode"] != JobCancelled {
+		if time.Now().After(deadline) {
+			t.Fatal("VS Code was not skipped")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	installer.finish("7zip.7zip", nil)
+	report := <-reports
+	installer.expectIdle(t)
+
+	want := []struct {
+		outcome BatchOutcome
+		reason  string
+	}{
+		{BatchSkipped, "cancelled"},
+		{BatchFailed, "exit status 1"},
+		{BatchInstalled, ""},
+		{BatchSkipped, "stopped after Node.js failed"},
+	}
+	for i, result := range report.Results {
+		if result.Outcome != want[i].outcome || result.Reason != want[i].reason {
+			t.Errorf("%s: %s %q, want %s %q", result.Name, result.Outcome, result.Reason, want[i].outcome, want[i].reason)
+		}
+	}
+	if failed := report.FailedApps(); len(failed) != 1 || failed[0].PackageID != "nodejs" {
+		t.Errorf("FailedApps = %v, want only Node.js", failed)
+	}
+}
+
+func TestBatchReportSummary(t *testing.T) {
+	report := testBatchReport()
+
+	want := "1 installed, 1 already installed, 1 skipped, 1 failed"
+	if got := report.Summary(); got != want {
+		t.Errorf("Summary = %q, want %q", got, want)
+	}
+
+	failed := report.FailedApps()
+	if len(failed) != 1 || failed[0].PackageID != "nodejs" {
+		t.Errorf("FailedApps = %v, want only nodejs", failed)
+	}
+	if got := (&BatchReport{}).Summary(); got != "No applications to install" {
+		t.Errorf("empty Summary = %q", got)
+	}
+}
+
+func TestBatchReportExport(t *testing.T) {
+	report := testBatchReport()
+
+	var csvOut bytes.Buffer
+	if err := report.WriteCSV(&csvOut); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(csvOut.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("got %d CSV lines, want a header and 4 results", len(lines))
+	}
+	if lines[0] != "Name,Package ID,Source,Version,Outcome,Reason" {
+		t.Errorf("unexpected header %q", lines[0])
+	}
+	if lines[3] != "Node.js,nodejs,choco,20.11.0,Failed,choco install of nodejs failed: exit status 1" {
+		t.Errorf("unexpected row %q", lines[3])
+	}
+
+	var jsonOut bytes.Buffer
+	if err := report.WriteJSON(&jsonOut); err != nil {
+		t.Fatal(err)
+	}
+	var decoded BatchReport
+	if err := json.Unmarshal(jsonOut.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.ListName != "Workstation" || len(decoded.Results) != 4 || decoded.Results[1].Version != "latest" {
+		t.Errorf("unexpected JSON report %+v", decoded)
+	}
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CommandRunner runs the package manager programs. Every backend goes through
+// one, so their commands can be recorded to a transcript and replayed in tests.
+type CommandRunner interface {
+	// Output runs the command and returns what it wrote to stdout
+	Output(ctx context.Context, name string, args ...string) ([]byte, error)
+	// Run runs the command and copies its combined output to output, which
+	// may be nil to discard it
+	Run(ctx context.Context, output io.Writer, name string, args ...string) error
+}
+
+// execRunner runs commands on this machine
+type execRunner struct{}
+
+func (execRunner) Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return newCommand(ctx, name, args...).Output()
+}
+
+func (execRunner) Run(ctx context.Context, output io.Writer, name string, args ...string) error {
+	cmd := newCommand(ctx, name, args...)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	return cmd.Run()
+}
+
+// runnerOrDefault returns runner, or the runner executing real commands if it is nil
+func runnerOrDefault(runner CommandRunner) CommandRunner {
+	if runner == nil {
+		return execRunner{}
+	}
+	return runner
+}
+
+// capturingRunner keeps a copy of the combined output of the commands it
+// runs, so a failure can be told apart by what the command printed
+type capturingRunner struct {
+	CommandRunner
+	output bytes.Buffer
+}
+
+func (r *capturingRunner) Run(ctx context.Context, output io.Writer, name string, args ...string) error {
+	var w io.Writer = &r.output
+	if output != nil {
+		w = io.MultiWriter(output, &r.output)
+	}
+	return r.CommandRunner.Run(ctx, w, name, args...)
+}
+
+func (r *capturingRunner) String() string {
+	return r.output.String()
+}
+
+// newCommand builds a backend command bound to ctx. Cancelling ctx kills the
+// whole process tree, so installers spawned by winget/choco/scoop stop as well.
+func newCommand(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	hideConsoleWindow(cmd)
+	cmd.Cancel = func() error {
+		return killProcessTree(cmd)
+	}
+	cmd.WaitDelay = 5 * time.Second
+	return cmd
+}
+
+// commandError prefers the context error over the exit status of a command
+// that was killed because ctx was cancelled or timed out
+func commandError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+// TranscriptEntry is one recorded command. Transcripts are JSON Lines files
+// with one entry per line, in the order the commands finished.
+type TranscriptEntry struct {
+	Command  string   `json:"command"` // Program name without directory or extension, e.g. "winget"
+	Args     []string `json:"args"`
+	ExitCode int      `json:"exit_code"`
+	Output   string   `json:"output"`          // Stdout for Output, combined output for Run
+	Error    string   `json:"error,omitempty"` // Set when the command could not run at all
+}
+
+// commandName strips the directory and extension from an executable, so an
+// overridden path replays the same as the default program
+func commandName(name string) string {
+	base := filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	return strings.ToLower(strings.TrimSuffix(base, filepath.Ext(base)))
+}
+
+// exitCode returns the exit code carried by err, 0 for nil and -1 when the
+// command did not exit by itself
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var coder interface{ ExitCode() int }
+	if errors.As(err, &coder) {
+		return coder.ExitCode()
+	}
+	return -1
+}
+
+// RecordingRunner passes commands on to another runner and appends each of
+// them to a transcript file
+type RecordingRunner struct {
+	runner CommandRunner
+	mutex  sync.Mutex
+	file   *os.File
+}
+
+// Path returns the transcript file commands are recorded to
+func (r *RecordingRunner) Path() string {
+	return r.file.Name()
+}
+
+// NewRecordingRunner records the commands run by runner to a new transcript
+// at path
+func NewRecordingRunner(runner CommandRunner, path string) (*RecordingRunner, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create transcript directory: %v", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transcript: %v", err)
+	}
+	return &RecordingRunner{runner: runnerOrDefault(runner), file: file}, nil
+}
+
+func (r *RecordingRunner) Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	output, err := r.runner.Output(ctx, name, args...)
+	r.record(name, args, output, err)
+	return output, err
+}
+
+func (r *RecordingRunner) Run(ctx context.Context, output io.Writer, name string, args ...string) error {
+	var recorded bytes.Buffer
+	writer := io.Writer(&recorded)
+	if output != nil {
+		writer = io.MultiWriter(&recorded, output)
+	}
+	err := r.runner.Run(ctx, writer, name, args...)
+	r.record(name, args, recorded.Bytes(), err)
+	return err
+}
+
+func (r *RecordingRunner) record(name string, args []string, output []byte, err error) {
+	entry := TranscriptEntry{
+		Command:  commandName(name),
+		Args:     args,
+		ExitCode: exitCode(err),
+		Output:   string(output),
+	}
+	if entry.ExitCode < 0 {
+		entry.Error = err.Error()
+	}
+
+	line, marshalErr := json.Marshal(entry)
+	if marshalErr != nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	// Recording is best effort and never fails the command itself
+	r.file.Write(append(line, '\n'))
+}
+
+// Close finishes the transcript
+func (r *RecordingRunner) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.file.Close()
+}
+
+// ReplayRunner answers commands from a transcript instead of running them.
+// Each entry is used once, so a command run twice replays two entries.
+type ReplayRunner struct {
+	mutex   sync.Mutex
+	entries []TranscriptEntry
+	used    []bool
+}
+
+// LoadTranscript reads a transcript written by a RecordingRunner
+func LoadTranscript(path string) (*ReplayRunner, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []TranscriptEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var entry TranscriptEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewReplayRunner(entries), nil
+}
+
+func NewReplayRunner(entries []TranscriptEntry) *ReplayRunner {
+	return &ReplayRunner{entries: entries, used: make([]bool, len(entries))}
+}
+
+func (r *ReplayRunner) Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	entry, err := r.next(ctx, name, args)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(entry.Output), entry.err()
+}
+
+func (r *ReplayRunner) Run(ctx context.Context, output io.Writer, name string, args ...string) error {
+	entry, err := r.next(ctx, name, args)
+	if err != nil {
+		return err
+	}
+	if output != nil {
+		io.WriteString(output, entry.Output)
+	}
+	return entry.err()
+}
+
+// next returns the first unused entry recorded for the command
+func (r *ReplayRunner) next(ctx context.Context, name string, args []string) (TranscriptEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return TranscriptEntry{}, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	command := commandName(name)
+	for i, entry := range r.entries {
+		if r.used[i] || entry.Command != command || !equalArgs(entry.Args, args) {
+			continue
+		}
+		r.used[i] = true
+		return entry, nil
+	}
+	return TranscriptEntry{}, fmt.Errorf("no recorded output for: %s %s", command, strings.Join(args, " "))
+}
+
+// Unused returns the entries that were never replayed
+func (r *ReplayRunner) Unused() []TranscriptEntry {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var unused []TranscriptEntry
+	for i, entry := range r.entries {
+		if !r.used[i] {
+			unused = append(unused, entry)
+		}
+	}
+	return unused
+}
+
+func equalArgs(a, b []string) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// err rebuilds the error the recorded command returned
+func (e TranscriptEntry) err() error {
+	switch {
+	case e.Error != "":
+		return errors.New(e.Error)
+	case e.ExitCode != 0:
+		return &replayExitError{code: e.ExitCode}
+	}
+	return nil
+}
+
+// replayExitError stands in for an *exec.ExitError of a replayed command
+type replayExitError struct {
+	code int
+}
+
+func (e *replayExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+func (e *replayExitError) ExitCode() int {
+	return e.code
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// replayTranscript loads a transcript from testdata/transcripts
+func replayTranscript(t *testing.T, name string) *ReplayRunner {
+	t.Helper()
+	runner, err := LoadTranscript(filepath.Join("testdata", "transcripts", name))
+	if err != nil {
+		t.Fatalf("failed to load transcript: %v", err)
+	}
+	return runner
+}
+
+// checkReplayed fails the test if some recorded commands were never run
+func checkReplayed(t *testing.T, runner *ReplayRunner) {
+	t.Helper()
+	for _, entry := range runner.Unused() {
+		t.Errorf("recorded command not run: %s %v", entry.Command, entry.Args)
+	}
+}
+
+func TestReplayWingetSearch(t *testing.T) {
+	runner := replayTranscript(t, "winget_search_truncated.jsonl")
+	winget := &WingetManager{}
+	winget.SetRunner(runner)
+
+	apps, err := winget.Search(context.Background(), "vcredist")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The truncated ID is resolved by the second recorded search
+	want := []appSummary{
+		{Name: "Microsoft Visual C++ 2015-2022 Redistributable (x64)", PackageID: "Microsoft.VCRedist.2015+.x64", Version: "14.38.33130.0", Repository: "winget"},
+		{Name: "Microsoft Visual C++ 2013 Redistributable (x64)", PackageID: "Microsoft.VCRedist.2013.x64", Version: "12.0.40664.0", Repository: "winget"},
+	}
+	if got := summarize(apps); !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+	checkReplayed(t, runner)
+}
+
+func TestReplayWingetInstall(t *testing.T) {
+	runner := replayTranscript(t, "winget_install.jsonl")
+	winget := &WingetManager{}
+	winget.SetRunner(runner)
+
+	var last ProgressEvent
+	err := winget.Install(context.Background(), "Notepad++.Notepad++", InstallOptions{}, func(event ProgressEvent) {
+		last = event
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.Phase != PhaseDone {
+		t.Errorf("last phase = %s, want %s", last.Phase, PhaseDone)
+	}
+	checkReplayed(t, runner)
+}
+
+func TestReplayChocoInstallNotFound(t *testing.T) {
+	runner := replayTranscript(t, "choco_install_not_found.jsonl")
+	choco := &ChocolateyManager{}
+	choco.SetRunner(runner)
+
+	err := choco.Install(context.Background(), "notepadplusplus-typo", InstallOptions{}, nil)
+	if !errors.Is(err, ErrPackageNotFound) {
+		t.Errorf("got %v, want %v", err, ErrPackageNotFound)
+	}
+	if code := exitCode(err); code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	checkReplayed(t, runner)
+}
+
+func TestReplayChocoSearchFailure(t *testing.T) {
+	choco := &ChocolateyManager{}
+	choco.SetRunner(replayTranscript(t, "choco_search_failed.jsonl"))
+
+	_, err := choco.Search(context.Background(), "git")
+	if err == nil {
+		t.Fatal("expected the failed search to return an error")
+	}
+	if code := exitCode(err); code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+}
+
+// Buckets come from the second recorded command, "scoop list"
+func TestReplayScoopUpgradable(t *testing.T) {
+	runner := replayTranscript(t, "scoop_upgradable.jsonl")
+	scoop := &ScoopManager{}
+	scoop.SetRunner(runner)
+
+	apps, err := scoop.GetUpgradableApps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, app := range apps {
+		got = append(got, app.PackageID+" "+app.InstalledVersion+" -> "+app.AvailableVersion)
+	}
+	want := []string{"main/git 2.42.0 -> 2.43.0", "main/nodejs 21.3.0 -> 21.4.0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	checkReplayed(t, runner)
+}
+
+// Scoop holds whatever is installed, so pinning another version must fail
+// instead of holding the wrong one
+func TestReplayScoopPinVersion(t *testing.T) {
+	list := TranscriptEntry{Command: "scoop", Args: []string{"list"}, Output: readFixture(t, "scoop", "list.txt")}
+	hold := TranscriptEntry{Command: "scoop", Args: []string{"hold", "git"}}
+
+	runner := NewReplayRunner([]TranscriptEntry{list, hold})
+	scoop := &ScoopManager{}
+	scoop.SetRunner(runner)
+	if err := scoop.Pin(context.Background(), "main/git", "2.43.0"); err != nil {
+		t.Fatal(err)
+	}
+	checkReplayed(t, runner)
+
+	runner = NewReplayRunner([]TranscriptEntry{list})
+	scoop.SetRunner(runner)
+	if err := scoop.Pin(context.Background(), "main/git", "2.42.0"); !errors.Is(err, ErrPinVersionUnsupported) {
+		t.Errorf("pinning another version returned %v", err)
+	}
+	checkReplayed(t, runner)
+
+	runner = NewReplayRunner([]TranscriptEntry{list})
+	scoop.SetRunner(runner)
+	if err := scoop.Pin(context.Background(), "extras/firefox", "120.0"); !errors.Is(err, ErrPinVersionUnsupported) {
+		t.Errorf("pinning an app that is not installed returned %v", err)
+	}
+}
+
+func TestReplayUnknownCommand(t *testing.T) {
+	runner := NewReplayRunner(nil)
+	if _, err := runner.Output(context.Background(), "winget", "list"); err == nil {
+		t.Error("expected an error for a command missing from the transcript")
+	}
+}
+
+func TestRecordingRunner(t *testing.T) {
+	source := NewReplayRunner([]TranscriptEntry{
+		{Command: "winget", Args: []string{"list"}, Output: "listed\n"},
+		{Command: "choco", Args: []string{"upgrade", "git", "-y"}, ExitCode: 1603, Output: "failed\n"},
+	})
+
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	recorder, err := NewRecordingRunner(source, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	// Overridden executables are recorded by their program name
+	recorder.Output(ctx, `C:\Tools\WinGet.exe`, "list")
+	recorder.Run(ctx, nil, "choco", "upgrade", "git", "-y")
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	replay, err := LoadTranscript(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(replay.entries, source.entries) {
+		t.Errorf("recorded %+v\nwant     %+v", replay.entries, source.entries)
+	}
+
+	output, err := replay.Output(ctx, "winget", "list")
+	if err != nil || string(output) != "listed\n" {
+		t.Errorf("replayed %q, %v", output, err)
+	}
+	if err := replay.Run(ctx, nil, "choco", "upgrade", "git", "-y"); exitCode(err) != 1603 {
+		t.Errorf("replayed error %v, want exit status 1603", err)
+	}
+}
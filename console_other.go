@@ -0,0 +1,25 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// hideConsoleWindow is a no-op outside Windows, where commands never open a console
+func hideConsoleWindow(cmd *exec.Cmd) {}
+
+// killProcessTree stops the command started by newCommand
+func killProcessTree(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return cmd.Process.Kill()
+}
+
+// restartComputer is only supported on Windows
+func restartComputer() error {
+	return fmt.Errorf("restarting is only supported on Windows")
+}
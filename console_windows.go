@@ -0,0 +1,48 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// Windows constants for hiding console windows
+const (
+	CREATE_NO_WINDOW = 0x08000000
+)
+
+// hideConsoleWindow configures the command to not show console windows
+func hideConsoleWindow(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow:    true,
+		CreationFlags: CREATE_NO_WINDOW,
+	}
+}
+
+// killProcessTree stops the command together with every process it started.
+// Windows does not kill children with their parent, so a cancelled winget or
+// choco call would otherwise leave the actual installer running.
+func killProcessTree(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+
+	taskkill := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid))
+	hideConsoleWindow(taskkill)
+	if err := taskkill.Run(); err != nil {
+		// Fall back to killing at least the direct child
+		return cmd.Process.Kill()
+	}
+	return nil
+}
+
+// restartComputer asks Windows to restart shortly, giving the user time to
+// save their work
+func restartComputer() error {
+	cmd := exec.Command("shutdown", "/r", "/t", "60", "/c", "Restarting to finish installing applications")
+	hideConsoleWindow(cmd)
+	return cmd.Run()
+}
@@ -13,22 +13,9 @@ import (
 )
 
 func InitDB() (*sql.DB, error) {
-	// Get user data directory
-	userDataDir, err := os.UserConfigDir()
+	appDataDir, err := getAppDataDir()
 	if err != nil {
-		// Fallback to user home directory
-		userDataDir, err = os.UserHomeDir()
-		if err != nil {
-			// Last resort fallback
-			userDataDir = "."
-		}
-	}
-
-	// Create PF Installer directory in user data
-	appDataDir := filepath.Join(userDataDir, "PF Installer")
-	err = os.MkdirAll(appDataDir, 0755)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create app data directory: %v", err)
+		return nil, err
 	}
 
 	// Database path in user data directory
@@ -39,212 +26,130 @@ func InitDB() (*sql.DB, error) {
 		return nil, err
 	}
 
-	if err := createTables(db); err != nil {
+	if err := migrateDatabase(db, dbPath); err != nil {
+		db.Close()
 		return nil, err
 	}
 
 	return db, nil
 }
 
-func createTables(db *sql.DB) error {
-	query := `
-	CREATE TABLE IF NOT EXISTS lists (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT NOT NULL UNIQUE,
-		description TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-	
-	CREATE TABLE IF NOT EXISTS saved_apps (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		list_id INTEGER NOT NULL,
-		name TEXT NOT NULL,
-		package_id TEXT NOT NULL,
-		version TEXT,
-		source TEXT NOT NULL,
-		description TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (list_id) REFERENCES lists(id) ON DELETE CASCADE,
-		UNIQUE(list_id, package_id)
-	);
-	
-	CREATE INDEX IF NOT EXISTS idx_package_id ON saved_apps(package_id);
-	CREATE INDEX IF NOT EXISTS idx_source ON saved_apps(source);
-	CREATE INDEX IF NOT EXISTS idx_list_id ON saved_apps(list_id);
-	CREATE INDEX IF NOT EXISTS idx_list_name ON lists(name);
-	
-	-- Create default list if it doesn't exist
-	INSERT OR IGNORE INTO lists (name, description) VALUES ('Default', 'Default saved applications list');
-	`
-
-	_, err := db.Exec(query)
-	return err
-}
-
-// List management functions
-func CreateList(db *sql.DB, name, description string) (int64, error) {
-	query := `INSERT INTO lists (name, description) VALUES (?, ?)`
-	result, err := db.Exec(query, name, description)
-	if err != nil {
-		return 0, err
-	}
-	return result.LastInsertId()
-}
-
-func GetLists(db *sql.DB) ([]*AppList, error) {
-	query := `SELECT id, name, description, created_at FROM lists ORDER BY name`
-
-	rows, err := db.Query(query)
+// getAppDataDir returns the directory holding the database and transcripts,
+// creating it if needed
+func getAppDataDir() (string, error) {
+	// Get user data directory
+	userDataDir, err := os.UserConfigDir()
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var lists []*AppList
-	for rows.Next() {
-		list := &AppList{}
-		err := rows.Scan(&list.ID, &list.Name, &list.Description, &list.CreatedAt)
+		// Fallback to user home directory
+		userDataDir, err = os.UserHomeDir()
 		if err != nil {
-			return nil, err
+			// Last resort fallback
+			userDataDir = "."
 		}
-		lists = append(lists, list)
 	}
 
-	return lists, rows.Err()
-}
-
-func GetListByName(db *sql.DB, name string) (*AppList, error) {
-	query := `SELECT id, name, description, created_at FROM lists WHERE name = ?`
-
-	list := &AppList{}
-	err := db.QueryRow(query, name).Scan(&list.ID, &list.Name, &list.Description, &list.CreatedAt)
+	// Create PF Installer directory in user data
+	appDataDir := filepath.Join(userDataDir, "PF Installer")
+	err = os.MkdirAll(appDataDir, 0755)
 	if err != nil {
-		return nil, err
+		return "", fmt.Errorf("failed to create app data directory: %v", err)
 	}
 
-	return list, nil
+	return appDataDir, nil
 }
 
-func GetListByID(db *sql.DB, listID int64) (*AppList, error) {
-	query := `SELECT id, name, description, created_at FROM lists WHERE id = ?`
+// Install history functions
 
-	list := &AppList{}
-	err := db.QueryRow(query, listID).Scan(&list.ID, &list.Name, &list.Description, &list.CreatedAt)
-	if err != nil {
-		return nil, err
+func AddInstallRecord(db *sql.DB, record *InstallRecord) (int64, error) {
+	// Apps installed from search results don't belong to a list
+	var listID sql.NullInt64
+	if record.ListID != 0 {
+		listID = sql.NullInt64{Int64: record.ListID, Valid: true}
 	}
 
-	return list, nil
-}
-
-func UpdateList(db *sql.DB, listID int64, name, description string) error {
-	query := `UPDATE lists SET name = ?, description = ? WHERE id = ?`
-	_, err := db.Exec(query, name, description, listID)
-	return err
-}
-
-func DeleteList(db *sql.DB, listID int64) error {
-	// Check if this is the default list (shouldn't be deleted)
-	if listID == 1 {
-		return fmt.Errorf("cannot delete the default list")
+	result, err := db.Exec(`INSERT INTO install_history
+		(name, package_id, source, version, list_id, list_name, started_at, finished_at, outcome, error, log)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		record.Name, record.PackageID, record.Source, record.Version, listID, record.ListName,
+		record.StartedAt.UTC(), record.FinishedAt.UTC(), string(record.Outcome), record.Error, record.Log)
+	if err != nil {
+		return 0, err
 	}
-
-	query := `DELETE FROM lists WHERE id = ?`
-	_, err := db.Exec(query, listID)
-	return err
-}
-
-// App management functions (updated for lists)
-func SaveAppToList(db *sql.DB, listID int64, app *AppInfo) error {
-	query := `
-	INSERT OR REPLACE INTO saved_apps (list_id, name, package_id, version, source, description)
-	VALUES (?, ?, ?, ?, ?, ?)
-	`
-	_, err := db.Exec(query, listID, app.Name, app.PackageID, app.Version, app.Source, app.Description)
-	return err
+	return result.LastInsertId()
 }
 
-func GetAppsInList(db *sql.DB, listID int64) ([]*AppInfo, error) {
-	query := `
-	SELECT id, name, package_id, version, source, description
-	FROM saved_apps
-	WHERE list_id = ?
-	ORDER BY name
-	`
-
-	rows, err := db.Query(query, listID)
-	if err != nil {
-		return nil, err
+// GetInstallHistory returns the recorded installations matching filter, newest first
+func GetInstallHistory(db *sql.DB, filter HistoryFilter) ([]*InstallRecord, error) {
+	query := `SELECT id, name, package_id, source, version, list_id, list_name, started_at, finished_at, outcome, error, log
+		FROM install_history WHERE 1 = 1`
+	var args []interface{}
+	if !filter.Since.IsZero() {
+		query += ` AND started_at >= ?`
+		args = append(args, filter.Since.UTC())
 	}
-	defer rows.Close()
-
-	var apps []*AppInfo
-	for rows.Next() {
-		app := &AppInfo{IsSaved: true, ListID: listID}
-		err := rows.Scan(&app.ID, &app.Name, &app.PackageID, &app.Version, &app.Source, &app.Description)
-		if err != nil {
-			return nil, err
-		}
-		apps = append(apps, app)
+	if filter.Outcome != "" {
+		query += ` AND outcome = ?`
+		args = append(args, string(filter.Outcome))
 	}
+	if filter.ListID != 0 {
+		query += ` AND list_id = ?`
+		args = append(args, filter.ListID)
+	}
+	query += ` ORDER BY started_at DESC, id DESC`
 
-	return apps, rows.Err()
-}
-
-func RemoveAppFromList(db *sql.DB, listID int64, packageID string) error {
-	query := `DELETE FROM saved_apps WHERE list_id = ? AND package_id = ?`
-	_, err := db.Exec(query, listID, packageID)
-	return err
-}
-
-func IsAppInList(db *sql.DB, listID int64, packageID string) (bool, error) {
-	query := `SELECT COUNT(*) FROM saved_apps WHERE list_id = ? AND package_id = ?`
-	var count int
-	err := db.QueryRow(query, listID, packageID).Scan(&count)
-	return count > 0, err
-}
-
-func GetAppListsContaining(db *sql.DB, packageID string) ([]*AppList, error) {
-	query := `
-	SELECT l.id, l.name, l.description, l.created_at
-	FROM lists l
-	INNER JOIN saved_apps sa ON l.id = sa.list_id
-	WHERE sa.package_id = ?
-	ORDER BY l.name
-	`
-
-	rows, err := db.Query(query, packageID)
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var lists []*AppList
+	var records []*InstallRecord
 	for rows.Next() {
-		list := &AppList{}
-		err := rows.Scan(&list.ID, &list.Name, &list.Description, &list.CreatedAt)
+		record := &InstallRecord{}
+		var listID sql.NullInt64
+		var outcome string
+		err := rows.Scan(&record.ID, &record.Name, &record.PackageID, &record.Source, &record.Version,
+			&listID, &record.ListName, &record.StartedAt, &record.FinishedAt, &outcome, &record.Error, &record.Log)
 		if err != nil {
 			return nil, err
 		}
-		lists = append(lists, list)
+		record.ListID = listID.Int64
+		record.Outcome = InstallOutcome(outcome)
+		records = append(records, record)
 	}
 
-	return lists, rows.Err()
+	return records, rows.Err()
 }
 
 // Legacy functions for backward compatibility (use default list)
 func SaveApp(db *sql.DB, app *AppInfo) error {
-	return SaveAppToList(db, 1, app) // Use default list (ID = 1)
+	list, err := GetDefaultList(db)
+	if err != nil {
+		return err
+	}
+	return SaveAppToList(db, list.ID, app)
 }
 
 func GetSavedApps(db *sql.DB) ([]*AppInfo, error) {
-	return GetAppsInList(db, 1) // Use default list (ID = 1)
+	list, err := GetDefaultList(db)
+	if err != nil {
+		return nil, err
+	}
+	return GetAppsInList(db, list.ID)
 }
 
-func RemoveSavedApp(db *sql.DB, packageID string) error {
-	return RemoveAppFromList(db, 1, packageID) // Use default list (ID = 1)
+func RemoveSavedApp(db *sql.DB, source, packageID string) error {
+	list, err := GetDefaultList(db)
+	if err != nil {
+		return err
+	}
+	return RemoveAppFromList(db, list.ID, source, packageID)
 }
 
-func IsAppSaved(db *sql.DB, packageID string) (bool, error) {
-	return IsAppInList(db, 1, packageID) // Use default list (ID = 1)
+func IsAppSaved(db *sql.DB, source, packageID string) (bool, error) {
+	list, err := GetDefaultList(db)
+	if err != nil {
+		return false, err
+	}
+	return IsAppInList(db, list.ID, source, packageID)
 }
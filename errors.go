@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Known reasons for a package manager command to fail. Backends wrap them,
+// mostly in a *PackageError, so callers branch with errors.Is.
+var (
+	ErrAlreadyInstalled      = errors.New("the package is already installed")
+	ErrNoApplicableUpgrade   = errors.New("no applicable upgrade was found")
+	ErrRebootRequired        = errors.New("a restart is required to finish")
+	ErrPackageNotFound       = errors.New("the package was not found")
+	ErrHashMismatch          = errors.New("the downloaded installer does not match its expected hash")
+	ErrBlockedByPolicy       = errors.New("the operation is blocked by a system policy")
+	ErrUserCancelled         = errors.New("the installer was cancelled")
+	ErrPinVersionUnsupported = errors.New("only the installed version can be pinned")
+)
+
+// errorHints tell the user what to do about each known failure
+var errorHints = map[error]string{
+	ErrAlreadyInstalled:      "Use Upgrade to move to a newer version, or uninstall it first to reinstall it.",
+	ErrNoApplicableUpgrade:   "The installed version is the newest one available for this machine, or the package is pinned.",
+	ErrRebootRequired:        "Restart Windows to complete the operation.",
+	ErrPackageNotFound:       "Check that the package ID is correct and its source is enabled, then search again.",
+	ErrHashMismatch:          "The publisher may have replaced the installer. Try again later or report it to the package maintainers.",
+	ErrBlockedByPolicy:       "Ask your administrator to allow installing this package.",
+	ErrUserCancelled:         "Start the operation again and accept the installer's prompts.",
+	ErrPinVersionUnsupported: "Scoop only supports holding the current version. Install the required version first, or set it to latest.",
+}
+
+// PackageError is a failed install, upgrade or uninstall
+type PackageError struct {
+	Source    string // Backend source name, e.g. "winget"
+	Operation string // "install", "upgrade" or "uninstall"
+	PackageID string
+	ExitCode  int
+	Kind      error // One of the Err* values above, nil for unknown exit codes
+	Err       error // Error returned by the command
+}
+
+func (e *PackageError) Error() string {
+	reason := e.Err.Error()
+	if e.Kind != nil {
+		reason = fmt.Sprintf("%s (exit code %s)", e.Kind, formatExitCode(e.ExitCode))
+	}
+	return fmt.Sprintf("%s %s of %s failed: %s", e.Source, e.Operation, e.PackageID, reason)
+}
+
+func (e *PackageError) Unwrap() []error {
+	if e.Kind != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Err}
+}
+
+// ErrorHint returns the remediation hint for a known failure, or an empty
+// string when err is not one
+func ErrorHint(err error) string {
+	for kind, hint := range errorHints {
+		if errors.Is(err, kind) {
+			return hint
+		}
+	}
+	return ""
+}
+
+// IsSoftFailure reports whether err means the package ended up in the state
+// that was asked for, so batch operations should not count it as a failure
+func IsSoftFailure(err error) bool {
+	return errors.Is(err, ErrAlreadyInstalled) || errors.Is(err, ErrNoApplicableUpgrade) ||
+		errors.Is(err, ErrRebootRequired)
+}
+
+// formatExitCode shows winget's HRESULTs in hex the way Microsoft documents them
+func formatExitCode(code int) string {
+	if code < 0 || code > 0xFFFF {
+		return fmt.Sprintf("0x%08X", uint32(code))
+	}
+	return fmt.Sprintf("%d", code)
+}
+
+// wingetExitCodes maps the HRESULTs winget exits with to known failures
+var wingetExitCodes = map[uint32]error{
+	0x8A150005: ErrUserCancelled,       // APPINSTALLER_CLI_ERROR_CTRL_SIGNAL_RECEIVED
+	0x8A150011: ErrHashMismatch,        // APPINSTALLER_CLI_ERROR_INSTALLER_HASH_MISMATCH
+	0x8A150014: ErrPackageNotFound,     // APPINSTALLER_CLI_ERROR_NO_APPLICATIONS_FOUND
+	0x8A15002B: ErrNoApplicableUpgrade, // APPINSTALLER_CLI_ERROR_UPDATE_NOT_APPLICABLE
+	0x8A15003A: ErrBlockedByPolicy,     // APPINSTALLER_CLI_ERROR_BLOCKED_BY_POLICY
+	0x8A150061: ErrAlreadyInstalled,    // APPINSTALLER_CLI_ERROR_PACKAGE_ALREADY_INSTALLED
+	0x8A150109: ErrRebootRequired,      // APPINSTALLER_CLI_ERROR_INSTALL_REBOOT_REQUIRED_TO_FINISH
+	0x8A15010A: ErrRebootRequired,      // APPINSTALLER_CLI_ERROR_INSTALL_REBOOT_REQUIRED_FOR_INSTALL
+	0x8A15010B: ErrRebootRequired,      // APPINSTALLER_CLI_ERROR_INSTALL_REBOOT_INITIATED
+	0x8A15010C: ErrUserCancelled,       // APPINSTALLER_CLI_ERROR_INSTALL_CANCELLED_BY_USER
+	0x8A15010D: ErrAlreadyInstalled,    // APPINSTALLER_CLI_ERROR_INSTALL_ALREADY_INSTALLED
+	0x8A15010F: ErrBlockedByPolicy,     // APPINSTALLER_CLI_ERROR_INSTALL_BLOCKED_BY_POLICY
+}
+
+func wingetErrorKind(operation string, code int) error {
+	return wingetExitCodes[uint32(code)]
+}
+
+// chocoErrorKind maps choco's exit codes, which pass on the MSI codes of the
+// installers it runs. Exit code 2 needs the useEnhancedExitCodes feature.
+func chocoErrorKind(operation string, code int) error {
+	switch code {
+	case 2:
+		if operation == "upgrade" {
+			return ErrNoApplicableUpgrade
+		}
+		if operation == "install" {
+			return ErrAlreadyInstalled
+		}
+	case 350, 1641, 3010: // Pending reboot, reboot initiated, reboot required
+		return ErrRebootRequired
+	case 1602: // ERROR_INSTALL_USEREXIT
+		return ErrUserCancelled
+	case 1605: // ERROR_UNKNOWN_PRODUCT
+		return ErrPackageNotFound
+	case 1625: // ERROR_INSTALL_PACKAGE_REJECTED
+		return ErrBlockedByPolicy
+	case 1638: // ERROR_PRODUCT_VERSION, another version is installed
+		return ErrAlreadyInstalled
+	}
+	return nil
+}
+
+// chocoNotFound is what choco prints for a package none of its sources has.
+// It exits with the generic code 1 in that case.
+const chocoNotFound = "the package was not found with the source(s) listed"
+
+// chocoOutputErrorKind is chocoErrorKind that also recognizes the failures
+// choco only reports in the output of the command that failed
+func chocoOutputErrorKind(output string) func(operation string, code int) error {
+	return func(operation string, code int) error {
+		if code == 1 && strings.Contains(strings.ToLower(output), chocoNotFound) {
+			return ErrPackageNotFound
+		}
+		return chocoErrorKind(operation, code)
+	}
+}
+
+// packageError turns the error of an install, upgrade or uninstall command
+// into a *PackageError. Cancellation, timeouts and commands that could not
+// start are returned unchanged.
+func packageError(ctx context.Context, source, operation, packageID string, err error, kindOf func(operation string, code int) error) error {
+	err = commandError(ctx, err)
+	var exited interface{ ExitCode() int }
+	if err == nil || !errors.As(err, &exited) {
+		return err
+	}
+
+	packageErr := &PackageError{Source: source, Operation: operation, PackageID: packageID, ExitCode: exited.ExitCode(), Err: err}
+	if kindOf != nil {
+		packageErr.Kind = kindOf(operation, packageErr.ExitCode)
+	}
+	return packageErr
+}
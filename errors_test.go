@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPackageErrorKinds(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation string
+		code      int
+		kindOf    func(operation string, code int) error
+		want      error
+	}{
+		{"winget no upgrade", "upgrade", 2316632107, wingetErrorKind, ErrNoApplicableUpgrade},
+		{"winget already installed", "install", 0x8A150061, wingetErrorKind, ErrAlreadyInstalled},
+		{"winget hash mismatch", "install", 0x8A150011, wingetErrorKind, ErrHashMismatch},
+		{"winget reboot", "install", 0x8A150109, wingetErrorKind, ErrRebootRequired},
+		{"winget policy", "install", 0x8A15003A, wingetErrorKind, ErrBlockedByPolicy},
+		{"winget not found", "install", 0x8A150014, wingetErrorKind, ErrPackageNotFound},
+		{"winget cancelled", "install", 0x8A15010C, wingetErrorKind, ErrUserCancelled},
+		{"winget negative HRESULT", "install", int(int32(-1978335135)), wingetErrorKind, ErrAlreadyInstalled},
+		{"choco reboot", "install", 3010, chocoErrorKind, ErrRebootRequired},
+		{"choco nothing to upgrade", "upgrade", 2, chocoErrorKind, ErrNoApplicableUpgrade},
+		{"choco already installed", "install", 2, chocoErrorKind, ErrAlreadyInstalled},
+		{"choco user cancelled", "install", 1602, chocoErrorKind, ErrUserCancelled},
+		{"choco generic failure", "install", 1, chocoErrorKind, nil},
+		{"choco not found", "install", 1, chocoOutputErrorKind("git-typo not installed. The package was not found with the source(s) listed."), ErrPackageNotFound},
+		{"choco failure with output", "install", 1, chocoOutputErrorKind("ERROR: Running installer failed"), nil},
+		{"choco reboot with output", "install", 3010, chocoOutputErrorKind(""), ErrRebootRequired},
+		{"scoop", "install", 1, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := packageError(context.Background(), "winget", tt.operation, "Some.Package", &replayExitError{code: tt.code}, tt.kindOf)
+
+			var packageErr *PackageError
+			if !errors.As(err, &packageErr) {
+				t.Fatalf("got %T, want *PackageError", err)
+			}
+			if packageErr.Kind != tt.want {
+				t.Errorf("kind = %v, want %v", packageErr.Kind, tt.want)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("errors.Is(%v) = false", tt.want)
+			}
+			if exitCode(err) != tt.code {
+				t.Errorf("exit code = %d, want %d", exitCode(err), tt.code)
+			}
+		})
+	}
+}
+
+func TestPackageErrorMessage(t *testing.T) {
+	err := packageError(context.Background(), "winget", "upgrade", "Git.Git", &replayExitError{code: 2316632107}, wingetErrorKind)
+	want := "winget upgrade of Git.Git failed: no applicable upgrade was found (exit code 0x8A15002B)"
+	if err.Error() != want {
+		t.Errorf("got %q\nwant %q", err.Error(), want)
+	}
+	if hint := ErrorHint(err); !strings.Contains(hint, "pinned") {
+		t.Errorf("unexpected hint %q", hint)
+	}
+	if !IsSoftFailure(err) {
+		t.Error("no applicable upgrade should not count as a failure")
+	}
+
+	unknown := packageError(context.Background(), "choco", "install", "git", &replayExitError{code: 1}, chocoErrorKind)
+	if unknown.Error() != "choco install of git failed: exit status 1" || ErrorHint(unknown) != "" || IsSoftFailure(unknown) {
+		t.Errorf("unexpected unknown failure %q", unknown)
+	}
+}
+
+func TestPackageErrorPassesThrough(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := packageError(ctx, "winget", "install", "Git.Git", &replayExitError{code: 1}, wingetErrorKind); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled command returned %v", err)
+	}
+
+	notStarted := errors.New(`exec: "winget": executable file not found in %PATH%`)
+	if err := packageError(context.Background(), "winget", "install", "Git.Git", notStarted, wingetErrorKind); err != notStarted {
+		t.Errorf("command that did not start returned %v", err)
+	}
+	if err := packageError(context.Background(), "winget", "install", "Git.Git", nil, wingetErrorKind); err != nil {
+		t.Errorf("success returned %v", err)
+	}
+}
+
+func TestReplayWingetUpgradeNotApplicable(t *testing.T) {
+	winget := &WingetManager{}
+	winget.SetRunner(NewReplayRunner([]TranscriptEntry{{
+		Command:  "winget",
+		Args:     []string{"upgrade", "--id", "Git.Git", "--exact", "--silent", "--accept-source-agreements", "--accept-package-agreements"},
+		ExitCode: 2316632107,
+		Output:   "No available upgrade found.\r\nNo newer package versions are available from the configured sources.\r\n",
+	}}))
+
+	err := winget.Upgrade(context.Background(), "Git.Git")
+	if !errors.Is(err, ErrNoApplicableUpgrade) {
+		t.Errorf("got %v, want ErrNoApplicableUpgrade", err)
+	}
+}
+
+func TestReplayChocoInstallRebootRequired(t *testing.T) {
+	choco := &ChocolateyManager{}
+	choco.SetRunner(NewReplayRunner([]TranscriptEntry{{
+		Command:  "choco",
+		Args:     []string{"install", "dotnet-8.0-runtime", "-y"},
+		ExitCode: 3010,
+		Output:   "Installing dotnet-8.0-runtime...\r\n dotnet-8.0-runtime may require a reboot.\r\n",
+	}}))
+
+	err := choco.Install(context.Background(), "dotnet-8.0-runtime", InstallOptions{}, nil)
+	if !errors.Is(err, ErrRebootRequired) || !IsSoftFailure(err) {
+		t.Errorf("got %v, want a reboot required soft failure", err)
+	}
+}
+
+func TestBackendCommandsReportCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, backend := range []PackageManager{&WingetManager{}, &ChocolateyManager{}, &ScoopManager{}} {
+		backend.SetRunner(NewReplayRunner(nil))
+		calls := map[string]func() error{
+			"search":    func() error { _, err := backend.Search(ctx, "git"); return err },
+			"installed": func() error { _, err := backend.GetInstalledApps(ctx); return err },
+			"upgrades":  func() error { _, err := backend.GetUpgradableApps(ctx); return err },
+			"details":   func() error { _, err := backend.Details(ctx, "git"); return err },
+			"pin":       func() error { return backend.Pin(ctx, "git", "") },
+			"unpin":     func() error { return backend.Unpin(ctx, "git") },
+		}
+		for name, call := range calls {
+			if err := call(); !errors.Is(err, context.Canceled) {
+				t.Errorf("%s %s returned %v", backend.Name(), name, err)
+			}
+		}
+	}
+}
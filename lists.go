@@ -0,0 +1,140 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// List management functions
+func CreateList(db *sql.DB, name, description string) (int64, error) {
+	query := `INSERT INTO lists (name, description) VALUES (?, ?)`
+	result, err := db.Exec(query, name, description)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+func GetLists(db *sql.DB) ([]*AppList, error) {
+	query := `SELECT id, name, description, is_default, created_at FROM lists ORDER BY name`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lists []*AppList
+	for rows.Next() {
+		list := &AppList{}
+		err := rows.Scan(&list.ID, &list.Name, &list.Description, &list.IsDefault, &list.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, list)
+	}
+
+	return lists, rows.Err()
+}
+
+func GetListByName(db *sql.DB, name string) (*AppList, error) {
+	query := `SELECT id, name, description, is_default, created_at FROM lists WHERE name = ?`
+
+	list := &AppList{}
+	err := db.QueryRow(query, name).Scan(&list.ID, &list.Name, &list.Description, &list.IsDefault, &list.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func GetListByID(db *sql.DB, listID int64) (*AppList, error) {
+	query := `SELECT id, name, description, is_default, created_at FROM lists WHERE id = ?`
+
+	list := &AppList{}
+	err := db.QueryRow(query, listID).Scan(&list.ID, &list.Name, &list.Description, &list.IsDefault, &list.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func GetDefaultList(db *sql.DB) (*AppList, error) {
+	query := `SELECT id, name, description, is_default, created_at FROM lists WHERE is_default = 1`
+
+	list := &AppList{}
+	err := db.QueryRow(query).Scan(&list.ID, &list.Name, &list.Description, &list.IsDefault, &list.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("no default list")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// SetDefaultList makes listID the default list in place of the current one
+func SetDefaultList(db *sql.DB, listID int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Clear the old default first, only one list may have the flag
+	if _, err := tx.Exec(`UPDATE lists SET is_default = 0 WHERE is_default = 1`); err != nil {
+		return err
+	}
+	result, err := tx.Exec(`UPDATE lists SET is_default = 1 WHERE id = ?`, listID)
+	if err != nil {
+		return err
+	}
+	if updated, err := result.RowsAffected(); err != nil {
+		return err
+	} else if updated == 0 {
+		return fmt.Errorf("list %d does not exist", listID)
+	}
+	return tx.Commit()
+}
+
+func UpdateList(db *sql.DB, listID int64, name, description string) error {
+	query := `UPDATE lists SET name = ?, description = ? WHERE id = ?`
+	_, err := db.Exec(query, name, description, listID)
+	return err
+}
+
+// DeleteList removes a list together with its saved apps and their tags.
+// Foreign keys are not enforced, so the apps are deleted here rather than by
+// ON DELETE CASCADE.
+func DeleteList(db *sql.DB, listID int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The default list can only go once another list has been made the default
+	var isDefault bool
+	err = tx.QueryRow(`SELECT is_default FROM lists WHERE id = ?`, listID).Scan(&isDefault)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if isDefault {
+		return fmt.Errorf("cannot delete the default list, set another list as the default first")
+	}
+
+	// The saved_apps_delete_tags trigger drops the apps' tags
+	if _, err := tx.Exec(`DELETE FROM saved_apps WHERE list_id = ?`, listID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM saved_app_tags)`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM lists WHERE id = ?`, listID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
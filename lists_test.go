@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+func TestDeleteListRemovesAppsAndTags(t *testing.T) {
+	db, path := openTestDB(t)
+	if err := migrateDatabase(db, path); err != nil {
+		t.Fatal(err)
+	}
+	workID, err := CreateList(db, "Work", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saveTestApp(t, db, 1, "winget", "Git.Git")
+	saveTestApp(t, db, workID, "winget", "Git.Git")
+	saveTestApp(t, db, workID, "choco", "slack")
+	if err := SetSavedAppTags(db, 1, "winget", "Git.Git", []string{"dev"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSavedAppTags(db, workID, "winget", "Git.Git", []string{"dev", "work"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSavedAppTags(db, workID, "choco", "slack", []string{"chat"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DeleteList(db, 1); err == nil {
+		t.Error("deleted the default list")
+	}
+	if err := DeleteList(db, workID); err != nil {
+		t.Fatal(err)
+	}
+
+	for table, want := range map[string]int{"saved_apps": 1, "saved_app_tags": 1, "tags": 1} {
+		var count int
+		if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		if count != want {
+			t.Errorf("%d rows left in %s, want %d", count, table, want)
+		}
+	}
+	if tags, err := GetAllTags(db); err != nil || len(tags) != 1 || tags[0] != "dev" {
+		t.Errorf("GetAllTags = %v, %v; want [dev]", tags, err)
+	}
+	lists, err := GetAppListsContaining(db, "choco", "slack")
+	if err != nil || len(lists) != 0 {
+		t.Errorf("slack is still in %d lists, err %v", len(lists), err)
+	}
+}
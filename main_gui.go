@@ -4,10 +4,15 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"image/color"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"runtime/debug"
 
@@ -24,9 +29,6 @@ const (
 	APP_ID    = "com.pfcode.application-installer"
 )
 
-// Global variable to track current theme state
-var isDarkTheme = true // Default to dark theme
-
 func main() {
 	// Set up logging to file
 	logFile, err := os.OpenFile("app.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
@@ -81,6 +83,15 @@ func main() {
 	if err != nil {
 		log.Printf("Database initialization failed: %v", err)
 		fmt.Printf("Database error: %v\n", err)
+		// The GUI build has no console, so explain the problem in the window
+		message := fmt.Sprintf("The application database could not be opened:\n\n%v", err)
+		if errors.Is(err, ErrSchemaTooNew) {
+			message += "\n\nPlease install the latest version of PF Installer."
+		}
+		myWindow.Resize(fyne.NewSize(600, 300))
+		myWindow.SetContent(widget.NewLabel(""))
+		dialog.ShowInformation("Database Error", message, myWindow)
+		myWindow.ShowAndRun()
 		return
 	}
 	defer db.Close()
@@ -90,6 +101,17 @@ func main() {
 	appManager := NewAppManager(db)
 	log.Println("AppManager created successfully")
 
+	log.Println("Loading settings...")
+	settings, err := appManager.LoadSettings()
+	if err != nil {
+		log.Printf("Loading settings failed: %v", err)
+	}
+	applyTheme(settings.Theme)
+	log.Println("Settings loaded successfully")
+
+	// Only now, with the executable overrides and enabled flags applied
+	appManager.DetectBackends()
+
 	log.Println("Creating main UI...")
 	content := createMainUI(myWindow, appManager)
 	log.Println("Main UI created successfully")
@@ -111,10 +133,14 @@ func main() {
 				log.Printf("Error auto-loading installed apps: %v", r)
 			}
 		}()
-		appManager.RefreshInstalledApps()
+		appManager.RefreshInstalledApps(context.Background())
 	}()
 
 	myApp.Run()
+
+	// Stop any winget/choco/scoop processes that are still running
+	log.Println("Cancelling running operations...")
+	appManager.Shutdown(10 * time.Second)
 	log.Println("Application closed normally")
 }
 
@@ -123,7 +149,10 @@ func createMainUI(window fyne.Window, appManager *AppManager) *fyne.Container {
 	// Create toolbar
 	toolbar := widget.NewToolbar(
 		widget.NewToolbarAction(theme.ViewRefreshIcon(), func() {
-			appManager.RefreshInstalledApps()
+			go appManager.RefreshInstalledApps(context.Background())
+		}),
+		widget.NewToolbarAction(theme.HistoryIcon(), func() {
+			showHistoryWindow(window, appManager)
 		}),
 		widget.NewToolbarSeparator(),
 		widget.NewToolbarAction(theme.HelpIcon(), func() {
@@ -133,7 +162,7 @@ func createMainUI(window fyne.Window, appManager *AppManager) *fyne.Container {
 			showAbout(window)
 		}),
 		widget.NewToolbarAction(theme.SettingsIcon(), func() {
-			showSettings(window)
+			showSettings(window, appManager)
 		}),
 	)
 	log.Println("Toolbar created successfully")
@@ -156,42 +185,108 @@ func createMainUI(window fyne.Window, appManager *AppManager) *fyne.Container {
 	return borderContainer
 }
 
-func showSettings(parent fyne.Window) {
+func showSettings(parent fyne.Window, appManager *AppManager) {
 	settingsWindow := fyne.CurrentApp().NewWindow("Settings")
-	settingsWindow.Resize(fyne.NewSize(450, 350))
+	settingsWindow.Resize(fyne.NewSize(600, 600))
 	settingsWindow.CenterOnScreen()
 
-	// Package Manager Settings
-	wingetCheck := widget.NewCheck("Enable Winget", nil)
-	chocoCheck := widget.NewCheck("Enable Chocolatey", nil)
-
-	// Set up validation callbacks after creating both checkboxes
-	wingetCheck.OnChanged = func(checked bool) {
-		// Prevent disabling both package managers
-		if !checked && !getChocoEnabled() {
-			// Show warning and revert the change
-			dialog.ShowError(fmt.Errorf("At least one package manager must be enabled.\nWinget will remain enabled."), settingsWindow)
-			wingetCheck.SetChecked(true) // Revert the change
-			return
+	// Package Manager Settings - one checkbox per registered backend
+	registry := appManager.Backends()
+	backendChecks := container.NewVBox()
+	for _, backend := range registry.All() {
+		backend := backend
+		var check *widget.Check
+		check = widget.NewCheck(fmt.Sprintf("Enable %s", backend.DisplayName()), func(checked bool) {
+			if checked == registry.IsEnabled(backend.Name()) {
+				return
+			}
+			// Prevent disabling every package manager
+			err := appManager.UpdateSettings(func(settings *Settings) {
+				settings.EnabledBackends[backend.Name()] = checked
+			})
+			if err != nil {
+				// Show warning and revert the change
+				if checked {
+					dialog.ShowError(err, settingsWindow)
+				} else {
+					dialog.ShowError(fmt.Errorf("At least one package manager must be enabled.\n%s will remain enabled.", backend.DisplayName()), settingsWindow)
+				}
+				check.SetChecked(!checked) // Revert the change
+				return
+			}
+			log.Printf("%s %s", backend.DisplayName(), map[bool]string{true: "enabled", false: "disabled"}[checked])
+		})
+		check.SetChecked(registry.IsEnabled(backend.Name()))
+		backendChecks.Add(check)
+	}
+
+	// Backend status - detected version and executable of each package manager
+	statusLabels := make(map[string]*widget.Label)
+	backendStatus := container.NewVBox()
+	updateBackendStatus := func() {
+		for _, backend := range registry.All() {
+			status, detected := registry.Status(backend.Name())
+			statusLabels[backend.Name()].SetText(fmt.Sprintf("%s: %s", backend.DisplayName(), describeBackendStatus(status, detected)))
 		}
-		setWingetEnabled(checked)
-		log.Printf("Winget %s", map[bool]string{true: "enabled", false: "disabled"}[checked])
+	}
+	redetect := func(sources ...string) {
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					// Handle panic gracefully
+				}
+			}()
+			if len(sources) == 0 {
+				registry.Detect(context.Background())
+			}
+			for _, source := range sources {
+				registry.DetectBackend(context.Background(), source)
+			}
+			updateBackendStatus()
+		}()
 	}
 
-	chocoCheck.OnChanged = func(checked bool) {
-		// Prevent disabling both package managers
-		if !checked && !getWingetEnabled() {
-			// Show warning and revert the change
-			dialog.ShowError(fmt.Errorf("At least one package manager must be enabled.\nChocolatey will remain enabled."), settingsWindow)
-			chocoCheck.SetChecked(true) // Revert the change
-			return
+	for _, backend := range registry.All() {
+		backend := backend
+
+		statusLabel := widget.NewLabel("")
+		statusLabel.Wrapping = fyne.TextWrapWord
+		statusLabels[backend.Name()] = statusLabel
+
+		// Executable override for installs that are not on PATH
+		pathEntry := widget.NewEntry()
+		pathEntry.SetPlaceHolder(fmt.Sprintf("%s (found on PATH)", backend.Executable()))
+		pathEntry.SetText(registry.ExecutablePath(backend.Name()))
+		applyPath := func() {
+			err := appManager.UpdateSettings(func(settings *Settings) {
+				if path := strings.TrimSpace(pathEntry.Text); path != "" {
+					settings.ExecutablePaths[backend.Name()] = path
+				} else {
+					delete(settings.ExecutablePaths, backend.Name())
+				}
+			})
+			if err != nil {
+				dialog.ShowError(err, settingsWindow)
+				return
+			}
+			log.Printf("%s executable set to %q", backend.DisplayName(), pathEntry.Text)
+			statusLabel.SetText(fmt.Sprintf("%s: detecting...", backend.DisplayName()))
+			redetect(backend.Name())
 		}
-		setChocoEnabled(checked)
-		log.Printf("Chocolatey %s", map[bool]string{true: "enabled", false: "disabled"}[checked])
+		pathEntry.OnSubmitted = func(string) { applyPath() }
+		applyButton := widget.NewButton("Apply", applyPath)
+
+		backendStatus.Add(statusLabel)
+		backendStatus.Add(container.NewBorder(nil, nil, nil, applyButton, pathEntry))
 	}
+	updateBackendStatus()
 
-	wingetCheck.SetChecked(getWingetEnabled())
-	chocoCheck.SetChecked(getChocoEnabled())
+	redetectButton := widget.NewButtonWithIcon("Re-detect", theme.ViewRefreshIcon(), func() {
+		for _, label := range statusLabels {
+			label.SetText(label.Text + " (detecting...)")
+		}
+		redetect()
+	})
 
 	// Add informational note
 	infoNote := widget.NewLabel("* At least one package manager must be enabled")
@@ -204,32 +299,149 @@ func showSettings(parent fyne.Window) {
 	themeRadio := widget.NewRadioGroup(
 		[]string{"Dark Theme", "Light Theme"},
 		func(value string) {
-			if value == "Dark Theme" && !isDarkTheme {
-				// Switch to dark theme
-				fyne.CurrentApp().Settings().SetTheme(&darkTheme{})
-				isDarkTheme = true
-				log.Println("Switched to dark theme")
-			} else if value == "Light Theme" && isDarkTheme {
-				// Switch to light theme
-				fyne.CurrentApp().Settings().SetTheme(&lightTheme{})
-				isDarkTheme = false
-				log.Println("Switched to light theme")
+			themeName := ThemeDark
+			if value == "Light Theme" {
+				themeName = ThemeLight
+			}
+			if themeName == appManager.Settings().Theme {
+				return
+			}
+			if err := appManager.UpdateSettings(func(settings *Settings) { settings.Theme = themeName }); err != nil {
+				dialog.ShowError(err, settingsWindow)
+				return
 			}
+			applyTheme(themeName)
+			log.Printf("Switched to %s theme", themeName)
 		},
 	)
 
 	// Set current theme selection
-	if isDarkTheme {
-		themeRadio.SetSelected("Dark Theme")
-	} else {
+	if appManager.Settings().Theme == ThemeLight {
 		themeRadio.SetSelected("Light Theme")
+	} else {
+		themeRadio.SetSelected("Dark Theme")
 	}
 
+	// Search Cache Settings
+	cacheLabels := make([]string, 0, len(searchCacheTTLs))
+	for _, option := range searchCacheTTLs {
+		cacheLabels = append(cacheLabels, option.Label)
+	}
+	cacheSelect := widget.NewSelect(cacheLabels, func(label string) {
+		for _, option := range searchCacheTTLs {
+			if option.Label != label || option.TTL == appManager.SearchCacheTTL() {
+				continue
+			}
+			if err := appManager.UpdateSettings(func(settings *Settings) { settings.SearchCacheTTL = option.TTL }); err != nil {
+				dialog.ShowError(err, settingsWindow)
+				return
+			}
+			log.Printf("Search cache lifetime set to %s", label)
+		}
+	})
+	currentTTL := appManager.SearchCacheTTL()
+	for _, option := range searchCacheTTLs {
+		if option.TTL == currentTTL {
+			cacheSelect.SetSelected(option.Label)
+		}
+	}
+
+	clearCacheButton := widget.NewButtonWithIcon("Clear Cache", theme.DeleteIcon(), func() {
+		if err := appManager.ClearSearchCache(); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to clear the search cache: %v", err), settingsWindow)
+			return
+		}
+		dialog.ShowInformation("Search Cache", "Cached search results have been cleared.", settingsWindow)
+	})
+
+	cacheNote := widget.NewLabel("* Older results are shown at once and refreshed in the background")
+	cacheNote.TextStyle = fyne.TextStyle{Italic: true}
+
+	// Install Queue Settings - how many installs each package manager runs at once
+	concurrencyOptions := make([]string, 0, maxInstallConcurrency)
+	for limit := 1; limit <= maxInstallConcurrency; limit++ {
+		concurrencyOptions = append(concurrencyOptions, strconv.Itoa(limit))
+	}
+	concurrencyForm := container.NewVBox()
+	for _, backend := range registry.All() {
+		backend := backend
+		concurrencySelect := widget.NewSelect(concurrencyOptions, func(value string) {
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit == appManager.InstallConcurrency(backend.Name()) {
+				return
+			}
+			if err := appManager.UpdateSettings(func(settings *Settings) { settings.InstallConcurrency[backend.Name()] = limit }); err != nil {
+				dialog.ShowError(err, settingsWindow)
+				return
+			}
+			log.Printf("%s runs %d installs at once", backend.DisplayName(), limit)
+		})
+		concurrencySelect.SetSelected(strconv.Itoa(appManager.InstallConcurrency(backend.Name())))
+		concurrencyForm.Add(container.NewBorder(nil, nil, widget.NewLabel(backend.DisplayName()+":"), nil, concurrencySelect))
+	}
+	concurrencyNote := widget.NewLabel("* Installs running at the same time per package manager. Winget is most reliable with 1")
+	concurrencyNote.Wrapping = fyne.TextWrapWord
+	concurrencyNote.TextStyle = fyne.TextStyle{Italic: true}
+
+	// Diagnostics - record package manager commands for bug reports
+	recordingLabel := widget.NewLabel("Saves every package manager command and its output to a transcript file")
+	recordingLabel.Wrapping = fyne.TextWrapWord
+	recordingLabel.TextStyle = fyne.TextStyle{Italic: true}
+	recordCheck := widget.NewCheck("Record package manager commands", func(checked bool) {
+		if checked == appManager.IsRecording() {
+			return
+		}
+		if err := appManager.UpdateSettings(func(settings *Settings) { settings.RecordCommands = checked }); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to start recording: %v", err), settingsWindow)
+			return
+		}
+		if !checked {
+			recordingLabel.SetText("Recording stopped")
+			log.Println("Stopped recording commands")
+			return
+		}
+		path := appManager.RecordingPath()
+		recordingLabel.SetText(fmt.Sprintf("Recording to %s", path))
+		log.Printf("Recording commands to %s", path)
+	})
+	recordCheck.SetChecked(appManager.IsRecording())
+	if path := appManager.RecordingPath(); path != "" {
+		recordingLabel.SetText(fmt.Sprintf("Recording to %s", path))
+	}
+
+	// Reset every setting, the window is reopened to show the defaults
+	resetButton := widget.NewButtonWithIcon("Reset to Defaults", theme.ContentUndoIcon(), func() {
+		dialog.ShowConfirm("Reset Settings", "Restore every setting to its default value?", func(reset bool) {
+			if !reset {
+				return
+			}
+			settings, err := appManager.ResetSettings()
+			if err != nil {
+				dialog.ShowError(err, settingsWindow)
+				return
+			}
+			applyTheme(settings.Theme)
+			log.Println("Settings reset to defaults")
+			settingsWindow.Close()
+			showSettings(parent, appManager)
+		}, settingsWindow)
+	})
+
 	form := &widget.Form{
 		Items: []*widget.FormItem{
-			{Text: "Package Managers", Widget: container.NewVBox(wingetCheck, chocoCheck, infoNote)},
+			{Text: "Package Managers", Widget: container.NewVBox(backendChecks, infoNote)},
+			{Text: "", Widget: widget.NewSeparator()}, // Visual separator
+			{Text: "Status", Widget: container.NewVBox(backendStatus, container.NewHBox(redetectButton))},
+			{Text: "", Widget: widget.NewSeparator()}, // Visual separator
+			{Text: "Search Cache", Widget: container.NewVBox(container.NewBorder(nil, nil, nil, clearCacheButton, cacheSelect), cacheNote)},
+			{Text: "", Widget: widget.NewSeparator()}, // Visual separator
+			{Text: "Install Queue", Widget: container.NewVBox(concurrencyForm, concurrencyNote)},
 			{Text: "", Widget: widget.NewSeparator()}, // Visual separator
 			{Text: "Appearance", Widget: container.NewVBox(themeLabel, themeRadio)},
+			{Text: "", Widget: widget.NewSeparator()}, // Visual separator
+			{Text: "Diagnostics", Widget: container.NewVBox(recordCheck, recordingLabel)},
+			{Text: "", Widget: widget.NewSeparator()}, // Visual separator
+			{Text: "Defaults", Widget: container.NewHBox(resetButton)},
 		},
 		OnSubmit: func() {
 			settingsWindow.Close()
@@ -245,10 +457,31 @@ func showSettings(parent fyne.Window) {
 		form,
 	)
 
-	settingsWindow.SetContent(content)
+	settingsWindow.SetContent(container.NewVScroll(content))
 	settingsWindow.Show()
 }
 
+// applyTheme switches the application to the dark or light theme
+func applyTheme(name string) {
+	if name == ThemeLight {
+		fyne.CurrentApp().Settings().SetTheme(&lightTheme{})
+	} else {
+		fyne.CurrentApp().Settings().SetTheme(&darkTheme{})
+	}
+}
+
+// describeBackendStatus summarizes what detection found out about a package manager
+func describeBackendStatus(status BackendStatus, detected bool) string {
+	switch {
+	case !detected:
+		return "not detected yet"
+	case !status.Available:
+		return fmt.Sprintf("not found at %s (%s)", status.Path, status.Error)
+	default:
+		return fmt.Sprintf("version %s at %s", status.Version, status.Path)
+	}
+}
+
 func createMainContent(appManager *AppManager) *container.Split {
 	log.Println("Creating left panel (search)...")
 	// Left panel - Search and filters
@@ -281,7 +514,7 @@ func showHelp(parent fyne.Window) {
 
 📋 OVERVIEW
 
-This application installer helps you discover, install, and organize applications from multiple sources (Winget, Chocolatey). You can create custom lists to organize your applications and manage them efficiently.
+This application installer helps you discover, install, and organize applications from multiple sources (Winget, Chocolatey, Scoop). You can create custom lists to organize your applications and manage them efficiently.
 
 
 🔍 SEARCHING & INSTALLING
@@ -289,6 +522,7 @@ This application installer helps you discover, install, and organize application
 • Enter search terms in the search box (e.g., "Visual Studio Code", "Discord")
 • Click "Search" to find applications from all enabled sources
 • Click "Install" next to any app to install it on your system
+• Click "Upgrade" or "Uninstall" on installed apps to update or remove them
 • Use "Clear" to reset your search and return to browsing mode
 
 
@@ -298,11 +532,13 @@ View Filters:
 • All Results: Shows search results or combined installed + saved apps
 • Installed Only: Shows only applications currently installed on your system
 • Saved Apps: Shows apps saved in the currently selected list
+• Updates Available: Shows installed apps with a newer version ("Upgrade All" updates them in one go)
 
 Source Filters:
-• All Sources: Shows apps from both Winget and Chocolatey
+• All Sources: Shows apps from every enabled package manager
 • Winget: Shows only Windows Package Manager apps
 • Chocolatey: Shows only Chocolatey package manager apps
+• Scoop: Shows only Scoop apps (package IDs include the bucket, e.g. extras/vscode)
 
 
 📋 LIST MANAGEMENT
@@ -310,7 +546,8 @@ Source Filters:
 • Use the dropdown to switch between your application lists
 • Click "Manage Lists" to create, edit, or delete lists
 • Each list can have a name and optional description
-• Default list cannot be deleted (but can be renamed)
+• The default list opens on startup and cannot be deleted (but can be renamed)
+• Use "Set as default" to make another list the default, after which the old one can be deleted
 • Selecting a list automatically switches to "Saved Apps" view
 
 
@@ -322,6 +559,15 @@ Source Filters:
 • Use "Manage Lists" button on saved apps for advanced list management
 
 
+🏷 TAGS
+
+• In "Saved Apps" view, click "Edit Tags" to label an app, e.g. dev, needs-license
+• Tags are typed comma separated and shown next to the lists an app is in
+• Use the Tags filter to show only apps with a tag
+• Searching in "Saved Apps" also matches tags
+• CSV exports include a Tags column, which imports read back
+
+
 🔄 MANAGING APPS IN LISTS
 
 Basic Method:
@@ -340,6 +586,7 @@ Advanced Method:
 
 • "Refresh Installed": Updates the list of installed applications
 • "Install All in List": Installs all apps from the currently selected list
+• Install Queue: Installs wait their turn below the application list, where they can be reordered, paused, cancelled or retried
 • List dropdown: Instantly switch between your organized lists
 • Auto-switch: Selecting a list automatically shows its contents
 
@@ -364,7 +611,7 @@ Advanced Method:
 
 🛠️ TROUBLESHOOTING
 
-• If no results appear, check that Winget/Chocolatey are properly installed
+• If no results appear, check that Winget/Chocolatey/Scoop are properly installed
 • Empty lists show helpful messages with the specific list name
 • Use "Refresh Installed" if recently installed apps don't appear
 • List changes update immediately when switching between lists
@@ -381,6 +628,7 @@ Advanced Method:
 
 • Windows 10/11 with Winget installed (usually included)
 • Chocolatey (optional, but recommended for more software options)
+• Scoop (optional, great for command line tools)
 • Administrator privileges may be required for some installations
 • Internet connection for searching and downloading applications
 
@@ -437,7 +685,7 @@ A modern GUI application for managing Windows applications using package manager
 
 🔧 Technical Details:
 • Built with Go and Fyne GUI framework
-• Uses Winget and Chocolatey package managers
+• Uses Winget, Chocolatey and Scoop package managers
 • SQLite3 database for saved applications
 • Cross-platform compatible (Windows focus)
 
@@ -445,11 +693,12 @@ A modern GUI application for managing Windows applications using package manager
 • Windows 10/11
 • Winget (included with Windows)
 • Chocolatey (optional)
+• Scoop (optional)
 
 📄 License: MIT License
 © 2025 PfCode. All rights reserved
 
-Special thanks to the open-source community and the developers of Fyne, Go, Winget, and Chocolatey.`
+Special thanks to the open-source community and the developers of Fyne, Go, Winget, Chocolatey, and Scoop.`
 
 	aboutLabel := widget.NewRichTextFromMarkdown(aboutContent)
 	aboutLabel.Wrapping = fyne.TextWrapWord
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// migration moves the database schema from version-1 to version. Each one
+// runs in its own transaction together with the PRAGMA user_version update.
+type migration struct {
+	version     int
+	description string
+	up          func(tx *sql.Tx) error
+}
+
+// migrations lists every schema change in order. Databases created before
+// versioning have user_version 0 but may already contain some of the tables
+// and columns, so the early steps only add what is missing. Never change a
+// released migration, append a new one instead.
+var migrations = []migration{
+	{1, "lists and saved apps", func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS lists (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			description TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS saved_apps (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			list_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			package_id TEXT NOT NULL,
+			version TEXT,
+			source TEXT NOT NULL,
+			description TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (list_id) REFERENCES lists(id) ON DELETE CASCADE,
+			UNIQUE(list_id, package_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_package_id ON saved_apps(package_id);
+		CREATE INDEX IF NOT EXISTS idx_source ON saved_apps(source);
+		CREATE INDEX IF NOT EXISTS idx_list_id ON saved_apps(list_id);
+		CREATE INDEX IF NOT EXISTS idx_list_name ON lists(name);
+
+		-- Create default list if it doesn't exist
+		INSERT OR IGNORE INTO lists (name, description) VALUES ('Default', 'Default saved applications list');
+		`)
+		return err
+	}},
+	{2, "package details of saved apps", func(tx *sql.Tx) error {
+		return addColumnsIfMissing(tx, "saved_apps", []columnDefinition{
+			{"publisher", "TEXT DEFAULT ''"},
+			{"homepage", "TEXT DEFAULT ''"},
+			{"license", "TEXT DEFAULT ''"},
+			{"tags", "TEXT DEFAULT ''"},
+			{"release_notes", "TEXT DEFAULT ''"},
+		})
+	}},
+	{3, "required versions and pins", func(tx *sql.Tx) error {
+		return addColumnsIfMissing(tx, "saved_apps", []columnDefinition{
+			{"required_version", "TEXT DEFAULT ''"},
+			{"pinned", "INTEGER DEFAULT 0"},
+		})
+	}},
+	{4, "install options", func(tx *sql.Tx) error {
+		return addColumnsIfMissing(tx, "saved_apps", []columnDefinition{
+			{"install_scope", "TEXT DEFAULT ''"},
+			{"install_architecture", "TEXT DEFAULT ''"},
+			{"install_location", "TEXT DEFAULT ''"},
+			{"install_override", "TEXT DEFAULT ''"},
+			{"install_custom_args", "TEXT DEFAULT ''"},
+			{"install_interactive", "INTEGER DEFAULT 0"},
+		})
+	}},
+	{5, "search cache", func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS search_cache (
+			source TEXT NOT NULL,
+			query TEXT NOT NULL,
+			results TEXT NOT NULL,
+			fetched_at DATETIME NOT NULL,
+			PRIMARY KEY (source, query)
+		);
+		`)
+		return err
+	}},
+	{6, "install history", func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS install_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			package_id TEXT NOT NULL,
+			source TEXT NOT NULL,
+			version TEXT DEFAULT '',
+			list_id INTEGER,
+			list_name TEXT DEFAULT '',
+			started_at DATETIME NOT NULL,
+			finished_at DATETIME NOT NULL,
+			outcome TEXT NOT NULL,
+			error TEXT DEFAULT '',
+			log TEXT DEFAULT ''
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_history_started_at ON install_history(started_at);
+		`)
+		return err
+	}},
+	{7, "settings", func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+		CREATE TABLE settings (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL
+		);
+		`)
+		return err
+	}},
+	{8, "saved apps unique per source", func(tx *sql.Tx) error {
+		// SQLite cannot change a table constraint, so the table is rebuilt
+		_, err := tx.Exec(`
+		CREATE TABLE saved_apps_new (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			list_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			package_id TEXT NOT NULL,
+			version TEXT,
+			source TEXT NOT NULL,
+			description TEXT,
+			publisher TEXT DEFAULT '',
+			homepage TEXT DEFAULT '',
+			license TEXT DEFAULT '',
+			tags TEXT DEFAULT '',
+			release_notes TEXT DEFAULT '',
+			required_version TEXT DEFAULT '',
+			pinned INTEGER DEFAULT 0,
+			install_scope TEXT DEFAULT '',
+			install_architecture TEXT DEFAULT '',
+			install_location TEXT DEFAULT '',
+			install_override TEXT DEFAULT '',
+			install_custom_args TEXT DEFAULT '',
+			install_interactive INTEGER DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (list_id) REFERENCES lists(id) ON DELETE CASCADE,
+			UNIQUE(list_id, source, package_id)
+		);
+
+		INSERT INTO saved_apps_new (id, list_id, name, package_id, version, source, description,
+			publisher, homepage, license, tags, release_notes, required_version, pinned,
+			install_scope, install_architecture, install_location, install_override, install_custom_args, install_interactive,
+			created_at)
+		SELECT id, list_id, name, package_id, version, source, description,
+			publisher, homepage, license, tags, release_notes, required_version, pinned,
+			install_scope, install_architecture, install_location, install_override, install_custom_args, install_interactive,
+			created_at
+		FROM saved_apps;
+
+		DROP TABLE saved_apps;
+		ALTER TABLE saved_apps_new RENAME TO saved_apps;
+
+		CREATE INDEX idx_package_id ON saved_apps(package_id);
+		CREATE INDEX idx_source ON saved_apps(source);
+		CREATE INDEX idx_list_id ON saved_apps(list_id);
+		`)
+		return err
+	}},
+	{9, "default list flag", func(tx *sql.Tx) error {
+		// List 1 used to be the default, fall back to the oldest list if it was
+		// removed by hand. The partial index allows only one default.
+		_, err := tx.Exec(`
+		ALTER TABLE lists ADD COLUMN is_default INTEGER NOT NULL DEFAULT 0;
+
+		UPDATE lists SET is_default = 1
+		WHERE id = COALESCE((SELECT id FROM lists WHERE id = 1), (SELECT MIN(id) FROM lists));
+
+		CREATE UNIQUE INDEX idx_lists_default ON lists(is_default) WHERE is_default = 1;
+		`)
+		return err
+	}},
+	{10, "saved app tags", func(tx *sql.Tx) error {
+		// Foreign keys are not enforced, so a trigger drops the tags of removed apps
+		_, err := tx.Exec(`
+		CREATE TABLE tags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE
+		);
+
+		CREATE TABLE saved_app_tags (
+			saved_app_id INTEGER NOT NULL,
+			tag_id INTEGER NOT NULL,
+			PRIMARY KEY (saved_app_id, tag_id),
+			FOREIGN KEY (saved_app_id) REFERENCES saved_apps(id) ON DELETE CASCADE,
+			FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+		);
+
+		CREATE INDEX idx_saved_app_tags_tag ON saved_app_tags(tag_id);
+
+		CREATE TRIGGER saved_apps_delete_tags AFTER DELETE ON saved_apps
+		BEGIN
+			DELETE FROM saved_app_tags WHERE saved_app_id = OLD.id;
+		END;
+		`)
+		return err
+	}},
+	{11, "remove apps of deleted lists", func(tx *sql.Tx) error {
+		// Deleting a list used to leave its apps and their tags behind
+		_, err := tx.Exec(`
+		DELETE FROM saved_apps WHERE list_id NOT IN (SELECT id FROM lists);
+		DELETE FROM saved_app_tags WHERE saved_app_id NOT IN (SELECT id FROM saved_apps);
+		DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM saved_app_tags);
+		`)
+		return err
+	}},
+}
+
+// ErrSchemaTooNew is returned for a database written by a newer version of
+// the application, which this version could damage
+var ErrSchemaTooNew = errors.New("the database was created by a newer version of PF Installer")
+
+// latestSchemaVersion is the version a fully migrated database has
+func latestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+func schemaVersion(db *sql.DB) (int, error) {
+	var version int
+	err := db.QueryRow("PRAGMA user_version").Scan(&version)
+	return version, err
+}
+
+// migrateDatabase brings the database at dbPath up to the latest schema. An
+// existing database is backed up next to dbPath first, and one from a newer
+// schema version is refused.
+func migrateDatabase(db *sql.DB, dbPath string) error {
+	version, err := schemaVersion(db)
+	if err != nil {
+		return fmt.Errorf("failed to read the database version: %w", err)
+	}
+	latest := latestSchemaVersion()
+	if version > latest {
+		return fmt.Errorf("%w (schema version %d, this version supports up to %d)", ErrSchemaTooNew, version, latest)
+	}
+	if version == latest {
+		return nil
+	}
+
+	empty, err := isEmptyDatabase(db)
+	if err != nil {
+		return err
+	}
+	if !empty && dbPath != "" {
+		backupPath := fmt.Sprintf("%s.v%d-%s.bak", dbPath, version, time.Now().Format("20060102_150405"))
+		if err := backupDatabase(db, backupPath); err != nil {
+			return fmt.Errorf("failed to back up the database before migrating: %w", err)
+		}
+	}
+
+	return migrateTo(db, latest)
+}
+
+// migrateTo runs every migration after the current version up to target
+func migrateTo(db *sql.DB, target int) error {
+	version, err := schemaVersion(db)
+	if err != nil {
+		return fmt.Errorf("failed to read the database version: %w", err)
+	}
+
+	for _, step := range migrations {
+		if step.version <= version || step.version > target {
+			continue
+		}
+		if err := runMigration(db, step); err != nil {
+			return fmt.Errorf("failed to migrate the database to version %d (%s): %w", step.version, step.description, err)
+		}
+	}
+	return nil
+}
+
+func runMigration(db *sql.DB, step migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := step.up(tx); err != nil {
+		return err
+	}
+	// PRAGMA does not take parameters
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", step.version)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// isEmptyDatabase reports whether the database has no tables yet
+func isEmptyDatabase(db *sql.DB) (bool, error) {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'").Scan(&count)
+	return count == 0, err
+}
+
+// backupDatabase writes a consistent copy of the database to path
+func backupDatabase(db *sql.DB, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("backup %s already exists", path)
+	}
+	_, err := db.Exec("VACUUM INTO ?", path)
+	return err
+}
+
+type columnDefinition struct{ name, definition string }
+
+// addColumnsIfMissing adds columns to a table created by an older version
+func addColumnsIfMissing(tx *sql.Tx, table string, columns []columnDefinition) error {
+	existing, err := tableColumns(tx, table)
+	if err != nil {
+		return err
+	}
+	for _, column := range columns {
+		if existing[column.name] {
+			continue
+		}
+		if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column.name, column.definition)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tableColumns returns the names of the columns of table
+func tableColumns(tx *sql.Tx, table string) (map[string]bool, error) {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid          int
+			name         string
+			columnType   string
+			notNull      int
+			defaultValue sql.NullString
+			primaryKey   int
+		)
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &primaryKey); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
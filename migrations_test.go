@@ -0,0 +1,382 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// openTestDB opens a new database file in a temporary directory
+func openTestDB(t *testing.T) (*sql.DB, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "applications.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, path
+}
+
+// loadFixture opens a database built from a SQL script in testdata/migrations
+func loadFixture(t *testing.T, name string) (*sql.DB, string) {
+	t.Helper()
+	script, err := os.ReadFile(filepath.Join("testdata", "migrations", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, path := openTestDB(t)
+	if _, err := db.Exec(string(script)); err != nil {
+		t.Fatalf("loading %s: %v", name, err)
+	}
+	return db, path
+}
+
+// dbSchema describes the tables, columns and indexes of a database, ignoring
+// column order since older databases had columns appended by ALTER TABLE
+func dbSchema(t *testing.T, db *sql.DB) map[string][]string {
+	t.Helper()
+	rows, err := db.Query("SELECT type, name, tbl_name FROM sqlite_master WHERE name NOT LIKE 'sqlite_%'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tables, indexes []string
+	for rows.Next() {
+		var kind, name, table string
+		if err := rows.Scan(&kind, &name, &table); err != nil {
+			t.Fatal(err)
+		}
+		switch kind {
+		case "table":
+			tables = append(tables, name)
+		case "index":
+			indexes = append(indexes, table+"."+name)
+		}
+	}
+	rows.Close()
+
+	sort.Strings(indexes)
+	schema := map[string][]string{"indexes": indexes}
+	for _, table := range tables {
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		columns, err := tableColumns(tx, table)
+		tx.Rollback()
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := make([]string, 0, len(columns))
+		for name := range columns {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		schema[table] = names
+	}
+	return schema
+}
+
+func backups(t *testing.T, dbPath string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(dbPath + ".v*.bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func expectVersion(t *testing.T, db *sql.DB, want int) {
+	t.Helper()
+	version, err := schemaVersion(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != want {
+		t.Errorf("schema version = %d, want %d", version, want)
+	}
+}
+
+// freshSchema is the schema of a database created by this version
+func freshSchema(t *testing.T) map[string][]string {
+	t.Helper()
+	db, path := openTestDB(t)
+	if err := migrateDatabase(db, path); err != nil {
+		t.Fatal(err)
+	}
+	return dbSchema(t, db)
+}
+
+func TestMigrateNewDatabase(t *testing.T) {
+	db, path := openTestDB(t)
+	if err := migrateDatabase(db, path); err != nil {
+		t.Fatal(err)
+	}
+	expectVersion(t, db, latestSchemaVersion())
+
+	// A new database has nothing worth backing up
+	if files := backups(t, path); len(files) != 0 {
+		t.Errorf("unexpected backups %v", files)
+	}
+
+	var lists int
+	if err := db.QueryRow("SELECT COUNT(*) FROM lists WHERE name = 'Default'").Scan(&lists); err != nil {
+		t.Fatal(err)
+	}
+	if lists != 1 {
+		t.Errorf("got %d Default lists, want 1", lists)
+	}
+
+	// Opening it again changes nothing
+	if err := migrateDatabase(db, path); err != nil {
+		t.Fatal(err)
+	}
+	if files := backups(t, path); len(files) != 0 {
+		t.Errorf("an up to date database was backed up: %v", files)
+	}
+}
+
+func TestMigrateHistoricalFixtures(t *testing.T) {
+	want := freshSchema(t)
+
+	fixtures := []string{
+		"v0_baseline.sql",
+		"v0_package_details.sql",
+		"v0_required_versions.sql",
+		"v0_install_options.sql",
+		"v0_search_cache.sql",
+		"v0_install_history.sql",
+	}
+	for _, fixture := range fixtures {
+		t.Run(fixture, func(t *testing.T) {
+			db, path := loadFixture(t, fixture)
+			if err := migrateDatabase(db, path); err != nil {
+				t.Fatal(err)
+			}
+			expectVersion(t, db, latestSchemaVersion())
+
+			if got := dbSchema(t, db); !reflect.DeepEqual(got, want) {
+				t.Errorf("migrated schema differs from a new database\ngot:  %v\nwant: %v", got, want)
+			}
+
+			// Saved apps survive the migration
+			var apps, lists int
+			if err := db.QueryRow("SELECT COUNT(*) FROM saved_apps").Scan(&apps); err != nil {
+				t.Fatal(err)
+			}
+			if err := db.QueryRow("SELECT COUNT(*) FROM lists").Scan(&lists); err != nil {
+				t.Fatal(err)
+			}
+			if apps != 3 || lists != 2 {
+				t.Errorf("got %d apps in %d lists, want 3 apps in 2 lists", apps, lists)
+			}
+
+			// The unmigrated database was backed up first
+			files := backups(t, path)
+			if len(files) != 1 {
+				t.Fatalf("got backups %v, want one", files)
+			}
+			backup, err := sql.Open("sqlite3", files[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer backup.Close()
+			expectVersion(t, backup, 0)
+		})
+	}
+}
+
+func TestMigrateFromEveryVersion(t *testing.T) {
+	want := freshSchema(t)
+
+	for _, step := range migrations[:len(migrations)-1] {
+		db, path := openTestDB(t)
+		if err := migrateTo(db, step.version); err != nil {
+			t.Fatalf("migrating to version %d: %v", step.version, err)
+		}
+		expectVersion(t, db, step.version)
+		if _, err := db.Exec(`INSERT INTO saved_apps (list_id, name, package_id, source) VALUES (1, 'Git', 'Git.Git', 'winget')`); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := migrateDatabase(db, path); err != nil {
+			t.Fatalf("migrating from version %d: %v", step.version, err)
+		}
+		expectVersion(t, db, latestSchemaVersion())
+		if got := dbSchema(t, db); !reflect.DeepEqual(got, want) {
+			t.Errorf("schema migrated from version %d differs from a new database\ngot:  %v\nwant: %v", step.version, got, want)
+		}
+		var apps int
+		if err := db.QueryRow("SELECT COUNT(*) FROM saved_apps WHERE package_id = 'Git.Git'").Scan(&apps); err != nil {
+			t.Fatal(err)
+		}
+		if apps != 1 {
+			t.Errorf("saved app lost migrating from version %d", step.version)
+		}
+		if files := backups(t, path); len(files) != 1 {
+			t.Errorf("got backups %v migrating from version %d, want one", files, step.version)
+		}
+	}
+}
+
+func TestMigrateRefusesNewerSchema(t *testing.T) {
+	db, path := openTestDB(t)
+	if err := migrateDatabase(db, path); err != nil {
+		t.Fatal(err)
+	}
+	newer := latestSchemaVersion() + 1
+	if _, err := db.Exec(fmt.Sprintf("PRAGMA user_version = %d", newer)); err != nil {
+		t.Fatal(err)
+	}
+
+	err := migrateDatabase(db, path)
+	if !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("got %v, want ErrSchemaTooNew", err)
+	}
+	expectVersion(t, db, newer)
+	if files := backups(t, path); len(files) != 0 {
+		t.Errorf("unexpected backups %v", files)
+	}
+}
+
+func TestMigrationRollsBackOnFailure(t *testing.T) {
+	original := migrations
+	defer func() { migrations = original }()
+
+	db, path := loadFixture(t, "v0_baseline.sql")
+	broken := latestSchemaVersion() + 1
+	migrations = append(append([]migration(nil), original...), migration{broken, "broken", func(tx *sql.Tx) error {
+		if _, err := tx.Exec("CREATE TABLE half_done (id INTEGER)"); err != nil {
+			return err
+		}
+		return errors.New("disk full")
+	}})
+
+	if err := migrateDatabase(db, path); err == nil {
+		t.Fatal("expected the broken migration to fail")
+	}
+	// Every step before the broken one is kept, the broken one left nothing behind
+	expectVersion(t, db, broken-1)
+	var tables int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'half_done'").Scan(&tables); err != nil {
+		t.Fatal(err)
+	}
+	if tables != 0 {
+		t.Error("the failed migration was not rolled back")
+	}
+}
+
+func TestSavedAppsUniquePerSource(t *testing.T) {
+	db, path := loadFixture(t, "v0_install_history.sql")
+	if err := migrateDatabase(db, path); err != nil {
+		t.Fatal(err)
+	}
+
+	// The same package ID from different sources are different apps
+	for _, source := range []string{"winget", "choco"} {
+		if _, err := db.Exec(`INSERT INTO saved_apps (list_id, name, package_id, source) VALUES (2, 'Git', 'git', ?)`, source); err != nil {
+			t.Fatalf("saving git from %s: %v", source, err)
+		}
+	}
+	if _, err := db.Exec(`INSERT INTO saved_apps (list_id, name, package_id, source) VALUES (2, 'Git', 'git', 'choco')`); err == nil {
+		t.Error("the same app was saved twice in one list")
+	}
+
+	var apps int
+	if err := db.QueryRow("SELECT COUNT(*) FROM saved_apps WHERE list_id = 2 AND package_id = 'git'").Scan(&apps); err != nil {
+		t.Fatal(err)
+	}
+	if apps != 2 {
+		t.Errorf("got %d git apps, want one per source", apps)
+	}
+}
+
+func TestDefaultListFlag(t *testing.T) {
+	defaultLists := func(db *sql.DB) []string {
+		t.Helper()
+		rows, err := db.Query("SELECT name FROM lists WHERE is_default = 1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var names []string
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, name)
+		}
+		return names
+	}
+
+	// List 1 was the hard-coded default
+	db, path := loadFixture(t, "v0_install_history.sql")
+	if err := migrateDatabase(db, path); err != nil {
+		t.Fatal(err)
+	}
+	if got := defaultLists(db); !reflect.DeepEqual(got, []string{"Default"}) {
+		t.Errorf("got default lists %v, want [Default]", got)
+	}
+	if _, err := db.Exec("UPDATE lists SET is_default = 1 WHERE name = 'Work'"); err == nil {
+		t.Error("a second list was made the default")
+	}
+
+	// Without list 1 the oldest remaining list becomes the default
+	db, path = loadFixture(t, "v0_install_history.sql")
+	if _, err := db.Exec("DELETE FROM lists WHERE id = 1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrateDatabase(db, path); err != nil {
+		t.Fatal(err)
+	}
+	if got := defaultLists(db); !reflect.DeepEqual(got, []string{"Work"}) {
+		t.Errorf("got default lists %v, want [Work]", got)
+	}
+}
+
+func TestMigrationRemovesAppsOfDeletedLists(t *testing.T) {
+	db, _ := openTestDB(t)
+	if err := migrateTo(db, 10); err != nil {
+		t.Fatal(err)
+	}
+	workList, err := db.Exec(`INSERT INTO lists (name) VALUES ('Work')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	workID, _ := workList.LastInsertId()
+	saveTestApp(t, db, 1, "winget", "Git.Git")
+	saveTestApp(t, db, workID, "choco", "slack")
+	if err := SetSavedAppTags(db, 1, "winget", "Git.Git", []string{"dev"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSavedAppTags(db, workID, "choco", "slack", []string{"chat"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deleted the way older versions did, leaving the app and its tag behind
+	if _, err := db.Exec(`DELETE FROM lists WHERE id = ?`, workID); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrateTo(db, latestSchemaVersion()); err != nil {
+		t.Fatal(err)
+	}
+
+	if tags, err := GetAllTags(db); err != nil || !reflect.DeepEqual(tags, []string{"dev"}) {
+		t.Errorf("GetAllTags = %v, %v; want [dev]", tags, err)
+	}
+	var apps int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM saved_apps`).Scan(&apps); err != nil {
+		t.Fatal(err)
+	}
+	if apps != 1 {
+		t.Errorf("%d saved apps left, want 1", apps)
+	}
+}
@@ -3,168 +3,487 @@ package main
 import (
 	"context"
 	"fmt"
-	"os/exec"
 	"regexp"
 	"strings"
-	"syscall"
+	"sync"
 	"time"
 )
 
 type PackageManager interface {
-	Search(query string) ([]*AppInfo, error)
-	Install(packageID string) error
-	GetInstalledApps() ([]*AppInfo, error)
-	IsAvailable() bool
+	Name() string        // Source key stored on AppInfo.Source, e.g. "winget"
+	DisplayName() string // Human readable name shown in the UI
+	Search(ctx context.Context, query string) ([]*AppInfo, error)
+	Install(ctx context.Context, packageID string, options InstallOptions, progress ProgressFunc) error // progress may be nil
+	Uninstall(ctx context.Context, packageID string) error
+	Upgrade(ctx context.Context, packageID string) error
+	GetInstalledApps(ctx context.Context) ([]*AppInfo, error)
+	GetUpgradableApps(ctx context.Context) ([]*AppInfo, error)
+	Details(ctx context.Context, packageID string) (*PackageDetails, error)
+	Pin(ctx context.Context, packageID, version string) error // Empty version pins the installed one
+	Unpin(ctx context.Context, packageID string) error
+	Executable() string                          // Program run for every command, the override or the default name
+	SetExecutable(path string)                   // Overrides the program for non-standard installs, empty restores the default
+	Version(ctx context.Context) (string, error) // Fails when the backend is not installed
+	SetRunner(runner CommandRunner)              // Runs commands through runner, nil for the real programs
 }
 
-type WingetManager struct{}
-type ChocolateyManager struct{}
+// InstallOptions adjusts how a package is installed. The zero value installs
+// the latest version silently with the backend defaults.
+type InstallOptions struct {
+	Version      string `json:"version"`      // Exact version to install, empty for the latest
+	Scope        string `json:"scope"`        // "user", "machine" or empty for the backend default
+	Architecture string `json:"architecture"` // "x64", "x86", "arm64" or empty for the best match
+	Location     string `json:"location"`     // Install directory, empty for the installer default
+	Override     string `json:"override"`     // Replaces the installer's own arguments
+	CustomArgs   string `json:"custom_args"`  // Appended to the installer's own arguments
+	Interactive  bool   `json:"interactive"`  // Show the installer UI instead of installing silently
+}
+
+// Install scopes and architectures offered in the install options editor
+var (
+	installScopes        = []string{"user", "machine"}
+	installArchitectures = []string{"x64", "x86", "arm64"}
+)
+
+type WingetManager struct{ command backendCommand }
+
+type ChocolateyManager struct{ command backendCommand }
 
 const commandTimeout = 30 * time.Second
 
-// Windows constants for hiding console windows
-const (
-	CREATE_NO_WINDOW = 0x08000000
-)
+// backendCommand holds how a backend runs its program: the executable,
+// overridable for installs that are not on PATH, and the runner its commands
+// go through
+type backendCommand struct {
+	mutex  sync.RWMutex
+	path   string
+	runner CommandRunner // Nil runs the real commands
+}
 
-// hideConsoleWindow configures the command to not show console windows
-func hideConsoleWindow(cmd *exec.Cmd) {
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		HideWindow:    true,
-		CreationFlags: CREATE_NO_WINDOW,
+func (b *backendCommand) executable(defaultName string) string {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if b.path != "" {
+		return b.path
 	}
+	return defaultName
 }
 
-func (w *WingetManager) IsAvailable() bool {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+func (b *backendCommand) setExecutable(path string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.path = strings.TrimSpace(path)
+}
+
+func (b *backendCommand) commands() CommandRunner {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return runnerOrDefault(b.runner)
+}
 
-	cmd := exec.CommandContext(ctx, "winget", "--version")
-	hideConsoleWindow(cmd)
-	return cmd.Run() == nil
+func (b *backendCommand) setRunner(runner CommandRunner) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.runner = runner
 }
 
-func (w *WingetManager) Search(query string) ([]*AppInfo, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+// versionRegexp finds versions like "v1.7.10861" or "2.2.2" in --version output
+var versionRegexp = regexp.MustCompile(`v?\d+(?:\.\d+)+`)
+
+// commandVersion runs "<executable> --version" and returns the version it reports
+func commandVersion(ctx context.Context, runner CommandRunner, executable string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "winget", "search", query, "--accept-source-agreements")
-	hideConsoleWindow(cmd)
-	output, err := cmd.Output()
+	output, err := runner.Output(ctx, executable, "--version")
 	if err != nil {
-		return nil, fmt.Errorf("winget search failed: %v", err)
+		return "", fmt.Errorf("%s --version failed: %w", executable, commandError(ctx, err))
+	}
+	return parseVersionOutput(string(output)), nil
+}
+
+// parseVersionOutput returns the first version in the output, or its first
+// line when no version number is found
+func parseVersionOutput(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if version := versionRegexp.FindString(line); version != "" {
+			return strings.TrimPrefix(version, "v")
+		}
 	}
+	return strings.TrimSpace(strings.SplitN(strings.TrimSpace(output), "\n", 2)[0])
+}
+
+func (w *WingetManager) Name() string {
+	return "winget"
+}
+
+func (w *WingetManager) DisplayName() string {
+	return "Winget"
+}
+
+func (w *WingetManager) Executable() string {
+	return w.command.executable("winget")
+}
+
+func (w *WingetManager) SetExecutable(path string) {
+	w.command.setExecutable(path)
+}
+
+func (w *WingetManager) Version(ctx context.Context) (string, error) {
+	return commandVersion(ctx, w.commands(), w.Executable())
+}
+
+func (w *WingetManager) SetRunner(runner CommandRunner) {
+	w.command.setRunner(runner)
+}
 
-	return parseWingetSearchOutput(string(output))
+func (w *WingetManager) commands() CommandRunner {
+	return w.command.commands()
 }
 
-func (w *WingetManager) Install(packageID string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+func (w *WingetManager) Search(ctx context.Context, query string) ([]*AppInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "winget", "install", packageID, "--accept-source-agreements", "--accept-package-agreements")
-	hideConsoleWindow(cmd)
-	return cmd.Run()
+	output, err := w.commands().Output(ctx, w.Executable(), "search", query, "--accept-source-agreements")
+	if err != nil {
+		return nil, fmt.Errorf("winget search failed: %w", commandError(ctx, err))
+	}
+
+	apps, err := parseWingetSearchOutput(string(output))
+	if err != nil {
+		return nil, err
+	}
+
+	w.resolveTruncatedIDs(ctx, apps, "search")
+	return apps, nil
 }
 
-func (w *WingetManager) GetInstalledApps() ([]*AppInfo, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+func (w *WingetManager) Install(ctx context.Context, packageID string, options InstallOptions, progress ProgressFunc) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "winget", "list")
-	hideConsoleWindow(cmd)
-	output, err := cmd.Output()
+	args, err := wingetInstallArgs(packageID, options)
 	if err != nil {
-		return nil, fmt.Errorf("winget list failed: %v", err)
+		return err
 	}
 
-	return parseWingetListOutput(string(output))
+	err = runWithProgress(ctx, w.commands(), parseWingetProgressLine, progress, w.Executable(), args...)
+	return packageError(ctx, w.Name(), "install", packageID, err, wingetErrorKind)
+}
+
+func wingetInstallArgs(packageID string, options InstallOptions) ([]string, error) {
+	args := []string{"install", "--id", packageID, "--exact"}
+	if options.Version != "" {
+		args = append(args, "--version", options.Version)
+	}
+	if options.Scope != "" {
+		args = append(args, "--scope", options.Scope)
+	}
+	if options.Architecture != "" {
+		args = append(args, "--architecture", options.Architecture)
+	}
+	if options.Location != "" {
+		args = append(args, "--location", options.Location)
+	}
+	if options.Override != "" {
+		args = append(args, "--override", options.Override)
+	}
+	if options.CustomArgs != "" {
+		args = append(args, "--custom", options.CustomArgs)
+	}
+	if options.Interactive {
+		args = append(args, "--interactive")
+	} else {
+		args = append(args, "--silent")
+	}
+	return append(args, "--accept-source-agreements", "--accept-package-agreements"), nil
 }
 
-func (c *ChocolateyManager) IsAvailable() bool {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (w *WingetManager) Pin(ctx context.Context, packageID, version string) error {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "choco", "--version")
-	hideConsoleWindow(cmd)
-	return cmd.Run() == nil
+	args := []string{"pin", "add", "--id", packageID, "--exact"}
+	if version != "" {
+		args = append(args, "--version", version)
+	}
+	args = append(args, "--accept-source-agreements")
+
+	if err := w.commands().Run(ctx, nil, w.Executable(), args...); err != nil {
+		return fmt.Errorf("winget pin add failed: %w", commandError(ctx, err))
+	}
+	return nil
 }
 
-func (c *ChocolateyManager) Search(query string) ([]*AppInfo, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+func (w *WingetManager) Unpin(ctx context.Context, packageID string) error {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "choco", "search", query, "--limit-output")
-	hideConsoleWindow(cmd)
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("chocolatey search failed: %v", err)
+	if err := w.commands().Run(ctx, nil, w.Executable(), "pin", "remove", "--id", packageID, "--exact", "--accept-source-agreements"); err != nil {
+		return fmt.Errorf("winget pin remove failed: %w", commandError(ctx, err))
 	}
+	return nil
+}
 
-	return parseChocoSearchOutput(string(output))
+func (w *WingetManager) Uninstall(ctx context.Context, packageID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	err := w.commands().Run(ctx, nil, w.Executable(), "uninstall", "--id", packageID, "--exact", "--silent", "--accept-source-agreements")
+	return packageError(ctx, w.Name(), "uninstall", packageID, err, wingetErrorKind)
 }
 
-func (c *ChocolateyManager) Install(packageID string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+func (w *WingetManager) Upgrade(ctx context.Context, packageID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "choco", "install", packageID, "-y")
-	hideConsoleWindow(cmd)
-	return cmd.Run()
+	err := w.commands().Run(ctx, nil, w.Executable(), "upgrade", "--id", packageID, "--exact", "--silent", "--accept-source-agreements", "--accept-package-agreements")
+	return packageError(ctx, w.Name(), "upgrade", packageID, err, wingetErrorKind)
 }
 
-func (c *ChocolateyManager) GetInstalledApps() ([]*AppInfo, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+func (w *WingetManager) GetInstalledApps(ctx context.Context) ([]*AppInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "choco", "list", "--local-only", "--limit-output")
-	hideConsoleWindow(cmd)
-	output, err := cmd.Output()
+	output, err := w.commands().Output(ctx, w.Executable(), "list")
 	if err != nil {
-		return nil, fmt.Errorf("chocolatey list failed: %v", err)
+		return nil, fmt.Errorf("winget list failed: %w", commandError(ctx, err))
 	}
 
-	return parseChocoListOutput(string(output))
+	apps, err := parseWingetListOutput(string(output))
+	if err != nil {
+		return nil, err
+	}
+
+	w.resolveTruncatedIDs(ctx, apps, "list")
+	return apps, nil
 }
 
-func parseWingetSearchOutput(output string) ([]*AppInfo, error) {
-	lines := strings.Split(output, "\n")
-	var apps []*AppInfo
+func (w *WingetManager) GetUpgradableApps(ctx context.Context) ([]*AppInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
 
-	// Skip header lines
-	dataStarted := false
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
+	output, err := w.commands().Output(ctx, w.Executable(), "upgrade", "--accept-source-agreements")
+	if err != nil {
+		return nil, fmt.Errorf("winget upgrade failed: %w", commandError(ctx, err))
+	}
+
+	apps, err := parseWingetUpgradeOutput(string(output))
+	if err != nil {
+		return nil, err
+	}
+
+	w.resolveTruncatedIDs(ctx, apps, "list")
+	return apps, nil
+}
 
-		// Look for separator line that indicates start of data
-		if strings.Contains(line, "---") {
-			dataStarted = true
+// resolveTruncatedIDs replaces the IDs winget shortened with "…" by running
+// command again filtered on the visible part of the ID. IDs that still can't
+// be resolved are left as they are.
+func (w *WingetManager) resolveTruncatedIDs(ctx context.Context, apps []*AppInfo, command string) {
+	for _, app := range apps {
+		if !isTruncated(app.PackageID) {
 			continue
 		}
 
-		if !dataStarted || strings.HasPrefix(line, "Name") {
+		prefix := strings.TrimSuffix(app.PackageID, wingetEllipsis)
+		output, err := w.commands().Output(ctx, w.Executable(), command, "--id", prefix, "--accept-source-agreements")
+		if err != nil {
 			continue
 		}
 
-		// Parse each line with regex to handle varying spacing
-		parts := regexp.MustCompile(`\s{2,}`).Split(line, -1)
-		if len(parts) >= 2 {
-			app := &AppInfo{
-				Name:      strings.TrimSpace(parts[0]),
-				PackageID: strings.TrimSpace(parts[1]),
-				Source:    "winget",
+		candidates, _ := parseWingetSearchOutput(string(output))
+		if match := matchTruncatedApp(app, candidates); match != nil {
+			app.PackageID = match.PackageID
+			if isTruncated(app.Name) {
+				app.Name = match.Name
 			}
-			if len(parts) >= 3 {
-				app.Version = strings.TrimSpace(parts[2])
-			}
-			apps = append(apps, app)
 		}
 	}
+}
 
-	return apps, nil
+func (w *WingetManager) Details(ctx context.Context, packageID string) (*PackageDetails, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	output, err := w.commands().Output(ctx, w.Executable(), "show", "--id", packageID, "--exact", "--accept-source-agreements")
+	if err != nil {
+		return nil, fmt.Errorf("winget show failed: %w", commandError(ctx, err))
+	}
+
+	return parseWingetShowOutput(string(output))
+}
+
+func (c *ChocolateyManager) Name() string {
+	return "chocolatey"
+}
+
+func (c *ChocolateyManager) DisplayName() string {
+	return "Chocolatey"
+}
+
+func (c *ChocolateyManager) Executable() string {
+	return c.command.executable("choco")
+}
+
+func (c *ChocolateyManager) SetExecutable(path string) {
+	c.command.setExecutable(path)
+}
+
+func (c *ChocolateyManager) Version(ctx context.Context) (string, error) {
+	return commandVersion(ctx, c.commands(), c.Executable())
+}
+
+func (c *ChocolateyManager) SetRunner(runner CommandRunner) {
+	c.command.setRunner(runner)
+}
+
+func (c *ChocolateyManager) commands() CommandRunner {
+	return c.command.commands()
+}
+
+func (c *ChocolateyManager) Search(ctx context.Context, query string) ([]*AppInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	output, err := c.commands().Output(ctx, c.Executable(), "search", query, "--limit-output")
+	if err != nil {
+		return nil, fmt.Errorf("chocolatey search failed: %w", commandError(ctx, err))
+	}
+
+	return parseChocoSearchOutput(string(output))
+}
+
+func (c *ChocolateyManager) Install(ctx context.Context, packageID string, options InstallOptions, progress ProgressFunc) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	args, err := chocoInstallArgs(packageID, options)
+	if err != nil {
+		return err
+	}
+
+	runner := &capturingRunner{CommandRunner: c.commands()}
+	err = runWithProgress(ctx, runner, parseChocoProgressLine, progress, c.Executable(), args...)
+	return packageError(ctx, c.Name(), "install", packageID, err, chocoOutputErrorKind(runner.String()))
+}
+
+func chocoInstallArgs(packageID string, options InstallOptions) ([]string, error) {
+	// Chocolatey always installs machine-wide, and only the licensed editions
+	// can redirect the install directory
+	if options.Scope == "user" {
+		return nil, fmt.Errorf("chocolatey can't install %s for the current user only", packageID)
+	}
+	if options.Location != "" {
+		return nil, fmt.Errorf("chocolatey can't change the install location of %s", packageID)
+	}
+
+	args := []string{"install", packageID, "-y"}
+	if options.Version != "" {
+		// Older versions may have to replace a newer one already installed
+		args = append(args, "--version", options.Version, "--allow-downgrade")
+	}
+	switch options.Architecture {
+	case "", "x64":
+	case "x86":
+		args = append(args, "--forcex86")
+	default:
+		return nil, fmt.Errorf("chocolatey can't install %s for %s", packageID, options.Architecture)
+	}
+	if options.Override != "" {
+		args = append(args, "--install-arguments", options.Override, "--override-arguments")
+	} else if options.CustomArgs != "" {
+		args = append(args, "--install-arguments", options.CustomArgs)
+	}
+	if options.Interactive {
+		args = append(args, "--not-silent")
+	}
+	return args, nil
+}
+
+func (c *ChocolateyManager) Pin(ctx context.Context, packageID, version string) error {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	args := []string{"pin", "add", "--name", packageID}
+	if version != "" {
+		args = append(args, "--version", version)
+	}
+
+	if err := c.commands().Run(ctx, nil, c.Executable(), args...); err != nil {
+		return fmt.Errorf("chocolatey pin add failed: %w", commandError(ctx, err))
+	}
+	return nil
+}
+
+func (c *ChocolateyManager) Unpin(ctx context.Context, packageID string) error {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	if err := c.commands().Run(ctx, nil, c.Executable(), "pin", "remove", "--name", packageID); err != nil {
+		return fmt.Errorf("chocolatey pin remove failed: %w", commandError(ctx, err))
+	}
+	return nil
+}
+
+func (c *ChocolateyManager) Uninstall(ctx context.Context, packageID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	runner := &capturingRunner{CommandRunner: c.commands()}
+	err := runner.Run(ctx, nil, c.Executable(), "uninstall", packageID, "-y")
+	return packageError(ctx, c.Name(), "uninstall", packageID, err, chocoOutputErrorKind(runner.String()))
+}
+
+func (c *ChocolateyManager) Upgrade(ctx context.Context, packageID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	runner := &capturingRunner{CommandRunner: c.commands()}
+	err := runner.Run(ctx, nil, c.Executable(), "upgrade", packageID, "-y")
+	return packageError(ctx, c.Name(), "upgrade", packageID, err, chocoOutputErrorKind(runner.String()))
+}
+
+func (c *ChocolateyManager) GetInstalledApps(ctx context.Context) ([]*AppInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	output, err := c.commands().Output(ctx, c.Executable(), "list", "--local-only", "--limit-output")
+	if err != nil {
+		return nil, fmt.Errorf("chocolatey list failed: %w", commandError(ctx, err))
+	}
+
+	return parseChocoListOutput(string(output))
+}
+
+func (c *ChocolateyManager) GetUpgradableApps(ctx context.Context) ([]*AppInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	output, err := c.commands().Output(ctx, c.Executable(), "outdated", "--limit-output")
+	if err != nil {
+		return nil, fmt.Errorf("chocolatey outdated failed: %w", commandError(ctx, err))
+	}
+
+	return parseChocoOutdatedOutput(string(output))
+}
+
+func (c *ChocolateyManager) Details(ctx context.Context, packageID string) (*PackageDetails, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	output, err := c.commands().Output(ctx, c.Executable(), "info", packageID)
+	if err != nil {
+		return nil, fmt.Errorf("chocolatey info failed: %w", commandError(ctx, err))
+	}
+
+	return parseChocoInfoOutput(string(output))
+}
+
+func parseWingetSearchOutput(output string) ([]*AppInfo, error) {
+	return wingetAppsFromTables(parseWingetTables(output)), nil
 }
 
 func parseWingetListOutput(output string) ([]*AppInfo, error) {
@@ -176,46 +495,984 @@ func parseWingetListOutput(output string) ([]*AppInfo, error) {
 	// Mark all as installed
 	for _, app := range apps {
 		app.IsInstalled = true
+		app.InstalledVersion = app.Version
 	}
 
 	return apps, nil
 }
 
-func parseChocoSearchOutput(output string) ([]*AppInfo, error) {
-	lines := strings.Split(output, "\n")
+// parseWingetUpgradeOutput parses the Name, Id, Version, Available, Source
+// table printed by "winget upgrade", whatever language the headers are in
+func parseWingetUpgradeOutput(output string) ([]*AppInfo, error) {
+	apps, err := parseWingetListOutput(output)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rows without an Available column have no pending upgrade
+	var upgradable []*AppInfo
+	for _, app := range apps {
+		if app.AvailableVersion != "" {
+			upgradable = append(upgradable, app)
+		}
+	}
+
+	return upgradable, nil
+}
+
+// wingetEllipsis ends names and IDs that winget shortened to fit the table
+const wingetEllipsis = "…"
+
+// wingetColumn is the meaning of a column in a winget table
+type wingetColumn int
+
+const (
+	wingetName wingetColumn = iota
+	wingetID
+	wingetVersion
+	wingetMatch
+	wingetAvailable
+	wingetSource
+)
+
+// wingetTable is one table printed by winget. Its columns are located by the
+// offset of each header word, since winget separates columns by a single space
+// and its separator line is one unbroken run of dashes. Header text is
+// localized, so it is never compared against; columns are identified by
+// position and content instead.
+type wingetTable struct {
+	columns []wingetColumn
+	rows    [][]string
+}
+
+// cell returns the value of the given column in row, or "" if the table has no such column
+func (t wingetTable) cell(row []string, column wingetColumn) string {
+	for i, c := range t.columns {
+		if c == column && i < len(row) {
+			return row[i]
+		}
+	}
+	return ""
+}
+
+// identifyWingetColumns works out what each column holds. Every table starts
+// with Name, Id and Version. Search results may add Match and Source, list
+// and upgrade output Available and Source, in that order.
+func identifyWingetColumns(count int, rows [][]string) []wingetColumn {
+	columns := []wingetColumn{wingetName, wingetID, wingetVersion}
+	switch count {
+	case 4:
+		columns = append(columns, classifyWingetColumn(rows, 3, true))
+	case 5:
+		columns = append(columns, classifyWingetColumn(rows, 3, false), wingetSource)
+	}
+	return columns[:min(count, len(columns))]
+}
+
+// classifyWingetColumn tells an optional column apart by its values: matches
+// read "Tag: value", versions start with a digit and source names do neither
+func classifyWingetColumn(rows [][]string, index int, canBeSource bool) wingetColumn {
+	versions := 0
+	values := 0
+	for _, row := range rows {
+		if index >= len(row) || row[index] == "" {
+			continue
+		}
+		value := row[index]
+		values++
+		if strings.ContainsAny(value, " :") {
+			return wingetMatch
+		}
+		if isVersionLike(value) {
+			versions++
+		}
+	}
+
+	if canBeSource && (values == 0 || versions < values) {
+		return wingetSource
+	}
+	return wingetAvailable
+}
+
+// isVersionLike reports whether value looks like a package version such as
+// "1.2.3", "v0.7" or winget's "< 2.0" ranges
+func isVersionLike(value string) bool {
+	value = strings.TrimLeft(value, "<> ")
+	value = strings.TrimPrefix(value, "v")
+	return value != "" && value[0] >= '0' && value[0] <= '9'
+}
+
+func wingetAppsFromTables(tables []wingetTable) []*AppInfo {
 	var apps []*AppInfo
+	for _, table := range tables {
+		for _, row := range table.rows {
+			id := table.cell(row, wingetID)
+			// Footer lines such as "2 upgrades available." leave no usable ID
+			if id == "" || strings.ContainsAny(id, " \t") {
+				continue
+			}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
+			apps = append(apps, &AppInfo{
+				Name:             table.cell(row, wingetName),
+				PackageID:        id,
+				Version:          table.cell(row, wingetVersion),
+				AvailableVersion: table.cell(row, wingetAvailable),
+				Repository:       table.cell(row, wingetSource),
+				Match:            table.cell(row, wingetMatch),
+				Source:           "winget",
+			})
+		}
+	}
+	return apps
+}
+
+// parseWingetTables finds every table in winget output. Besides the main
+// table, "winget upgrade" may print a second one for packages that need
+// explicit targeting.
+func parseWingetTables(output string) []wingetTable {
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		lines[i] = cleanWingetLine(line)
+	}
+
+	var tables []wingetTable
+	for i := 1; i < len(lines); i++ {
+		if !isWingetSeparator(lines[i]) || strings.TrimSpace(lines[i-1]) == "" {
 			continue
 		}
 
-		parts := strings.Split(line, "|")
-		if len(parts) >= 2 {
-			app := &AppInfo{
-				Name:      strings.TrimSpace(parts[0]),
-				PackageID: strings.TrimSpace(parts[0]),
-				Version:   strings.TrimSpace(parts[1]),
-				Source:    "chocolatey",
+		starts := wingetColumnStarts(lines[i-1])
+		if len(starts) < 2 {
+			continue
+		}
+
+		var table wingetTable
+		for j := i + 1; j < len(lines); j++ {
+			line := lines[j]
+			if strings.TrimSpace(line) == "" || isWingetSeparator(line) {
+				break
 			}
-			apps = append(apps, app)
+			// The header of a following table
+			if j+1 < len(lines) && isWingetSeparator(lines[j+1]) {
+				break
+			}
+			if !fitsColumns(line, starts) {
+				break
+			}
+			table.rows = append(table.rows, splitCells(line, starts))
 		}
+		table.columns = identifyWingetColumns(len(starts), table.rows)
+		tables = append(tables, table)
 	}
 
-	return apps, nil
+	return tables
 }
 
-func parseChocoListOutput(output string) ([]*AppInfo, error) {
-	apps, err := parseChocoSearchOutput(output)
-	if err != nil {
-		return nil, err
+// cleanWingetLine drops the spinner winget draws with backspaces and carriage
+// returns before it prints a table
+func cleanWingetLine(line string) string {
+	line = strings.TrimRight(line, "\r")
+	if i := strings.LastIndexAny(line, "\r\b"); i >= 0 {
+		line = line[i+1:]
 	}
+	return strings.TrimRight(line, " ")
+}
 
-	// Mark all as installed
-	for _, app := range apps {
-		app.IsInstalled = true
+func isWingetSeparator(line string) bool {
+	line = strings.TrimSpace(line)
+	return len(line) >= 3 && strings.Trim(line, "-") == ""
+}
+
+// wingetColumnStarts returns the cell offset of every word in the header line
+func wingetColumnStarts(header string) []int {
+	var starts []int
+	pos := 0
+	previous := ' '
+	for _, r := range header {
+		if r != ' ' && previous == ' ' {
+			starts = append(starts, pos)
+		}
+		previous = r
+		pos += cellWidth(r)
 	}
+	return starts
+}
 
-	return apps, nil
+// fitsColumns reports whether every column boundary of line falls on a space,
+// which is true for table rows and false for most free text after a table
+func fitsColumns(line string, starts []int) bool {
+	pos := 0
+	next := 1
+	for _, r := range line {
+		width := cellWidth(r)
+		for next < len(starts) && pos+width >= starts[next] {
+			if pos+width == starts[next] && r != ' ' {
+				return false
+			}
+			if pos+width > starts[next] {
+				// A wide character straddles the boundary
+				return false
+			}
+			next++
+		}
+		pos += width
+	}
+	return true
+}
+
+// splitCells cuts line at the given cell offsets and trims each value
+func splitCells(line string, starts []int) []string {
+	cells := make([]string, len(starts))
+	var cell strings.Builder
+	column := 0
+	pos := 0
+	for _, r := range line {
+		for column+1 < len(starts) && pos >= starts[column+1] {
+			cells[column] = strings.TrimSpace(cell.String())
+			cell.Reset()
+			column++
+		}
+		cell.WriteRune(r)
+		pos += cellWidth(r)
+	}
+	cells[column] = strings.TrimSpace(cell.String())
+	return cells
+}
+
+// cellWidth returns how many terminal cells winget uses for r. East Asian wide
+// and fullwidth characters take two.
+func cellWidth(r rune) int {
+	switch {
+	case r >= 0x1100 && r <= 0x115F,
+		r >= 0x2E80 && r <= 0x303E,
+		r >= 0x3041 && r <= 0x33FF,
+		r >= 0x3400 && r <= 0x4DBF,
+		r >= 0x4E00 && r <= 0x9FFF,
+		r >= 0xA000 && r <= 0xA4CF,
+		r >= 0xAC00 && r <= 0xD7A3,
+		r >= 0xF900 && r <= 0xFAFF,
+		r >= 0xFE30 && r <= 0xFE4F,
+		r >= 0xFF00 && r <= 0xFF60,
+		r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x1F300 && r <= 0x1F64F,
+		r >= 0x20000 && r <= 0x3FFFD:
+		return 2
+	}
+	return 1
+}
+
+// isTruncated reports whether winget shortened value to fit the table
+func isTruncated(value string) bool {
+	return strings.HasSuffix(value, wingetEllipsis)
+}
+
+// matchTruncatedApp picks the candidate whose full ID and name start with the
+// shortened ones of app. It returns nil unless exactly one candidate fits.
+func matchTruncatedApp(app *AppInfo, candidates []*AppInfo) *AppInfo {
+	idPrefix := strings.TrimSuffix(app.PackageID, wingetEllipsis)
+	namePrefix := strings.TrimSuffix(app.Name, wingetEllipsis)
+
+	var match *AppInfo
+	for _, candidate := range candidates {
+		if isTruncated(candidate.PackageID) || !strings.HasPrefix(candidate.PackageID, idPrefix) {
+			continue
+		}
+		if isTruncated(app.Name) && !isTruncated(candidate.Name) {
+			if !strings.HasPrefix(candidate.Name, namePrefix) {
+				continue
+			}
+		} else if candidate.Name != app.Name {
+			continue
+		}
+		if app.Repository != "" && candidate.Repository != "" && candidate.Repository != app.Repository {
+			continue
+		}
+		if match != nil {
+			return nil
+		}
+		match = candidate
+	}
+	return match
+}
+
+// wingetShowLabels maps the field labels "winget show" prints in each display
+// language to the English ones. winget has no option to choose its language
+// and no structured output for show, so the labels are matched as printed.
+var wingetShowLabels = map[string]string{
+	"publisher":     "Publisher",
+	"homepage":      "Homepage",
+	"license":       "License",
+	"description":   "Description",
+	"tags":          "Tags",
+	"release notes": "Release Notes",
+	// German
+	"herausgeber":      "Publisher",
+	"beschreibung":     "Description",
+	"startseite":       "Homepage",
+	"lizenz":           "License",
+	"versionshinweise": "Release Notes",
+	"markierungen":     "Tags",
+	// French
+	"éditeur":              "Publisher",
+	"page d’accueil":       "Homepage",
+	"page d'accueil":       "Homepage",
+	"licence":              "License",
+	"notes de publication": "Release Notes",
+	"balises":              "Tags",
+	// Japanese
+	"発行元":      "Publisher",
+	"説明":       "Description",
+	"ホームページ":   "Homepage",
+	"ライセンス":    "License",
+	"リリース ノート": "Release Notes",
+	"タグ":       "Tags",
+}
+
+// parseWingetShowOutput reads the "Key: value" fields printed by "winget show".
+// Multi-line values such as Tags and Release Notes continue on indented lines.
+// Output whose labels are all in an unknown language is an error rather
+// than empty details.
+func parseWingetShowOutput(output string) (*PackageDetails, error) {
+	fields := make(map[string][]string)
+	found := false
+	key := ""
+	for _, line := range strings.Split(output, "\n") {
+		line = cleanWingetLine(line)
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") {
+			name, value, ok := strings.Cut(line, ":")
+			if !ok {
+				// "Found Visual Studio Code [Microsoft.VisualStudioCode]"
+				key = ""
+				continue
+			}
+			found = true
+			key = wingetShowLabels[strings.ToLower(strings.TrimSpace(name))]
+			if value = strings.TrimSpace(value); key != "" && value != "" {
+				fields[key] = append(fields[key], value)
+			}
+			continue
+		}
+
+		if key != "" {
+			fields[key] = append(fields[key], strings.TrimSpace(line))
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no package details found")
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("winget show printed no known fields, its display language may not be supported")
+	}
+
+	return &PackageDetails{
+		Publisher:    strings.Join(fields["Publisher"], " "),
+		Homepage:     strings.Join(fields["Homepage"], " "),
+		License:      strings.Join(fields["License"], " "),
+		Description:  strings.Join(fields["Description"], "\n"),
+		Tags:         fields["Tags"],
+		ReleaseNotes: strings.Join(fields["Release Notes"], "\n"),
+	}, nil
+}
+
+func parseChocoSearchOutput(output string) ([]*AppInfo, error) {
+	lines := strings.Split(output, "\n")
+	var apps []*AppInfo
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) >= 2 {
+			app := &AppInfo{
+				Name:      strings.TrimSpace(parts[0]),
+				PackageID: strings.TrimSpace(parts[0]),
+				Version:   strings.TrimSpace(parts[1]),
+				Source:    "chocolatey",
+			}
+			apps = append(apps, app)
+		}
+	}
+
+	return apps, nil
+}
+
+func parseChocoListOutput(output string) ([]*AppInfo, error) {
+	apps, err := parseChocoSearchOutput(output)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mark all as installed
+	for _, app := range apps {
+		app.IsInstalled = true
+		app.InstalledVersion = app.Version
+	}
+
+	return apps, nil
+}
+
+// parseChocoOutdatedOutput parses "choco outdated --limit-output" rows in the
+// form name|current|available|pinned
+func parseChocoOutdatedOutput(output string) ([]*AppInfo, error) {
+	lines := strings.Split(output, "\n")
+	var apps []*AppInfo
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) >= 3 {
+			app := &AppInfo{
+				Name:             strings.TrimSpace(parts[0]),
+				PackageID:        strings.TrimSpace(parts[0]),
+				Version:          strings.TrimSpace(parts[1]),
+				InstalledVersion: strings.TrimSpace(parts[1]),
+				AvailableVersion: strings.TrimSpace(parts[2]),
+				Source:           "chocolatey",
+				IsInstalled:      true,
+			}
+			if len(parts) >= 4 {
+				app.Pinned = strings.EqualFold(strings.TrimSpace(parts[3]), "true")
+			}
+			apps = append(apps, app)
+		}
+	}
+
+	return apps, nil
+}
+
+// chocoInfoFieldRegexp matches the " Key: value" lines of "choco info". One line
+// may hold several fields separated by " | ".
+var chocoInfoFieldRegexp = regexp.MustCompile(`^ ([A-Z][A-Za-z ]*?): ?(.*)$`)
+
+// parseChocoInfoOutput reads the package fields printed by "choco info". Other
+// indented lines continue the previous field.
+func parseChocoInfoOutput(output string) (*PackageDetails, error) {
+	fields := make(map[string][]string)
+	key := ""
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r ")
+
+		if match := chocoInfoFieldRegexp.FindStringSubmatch(line); match != nil {
+			key = match[1]
+			for _, part := range strings.Split(line, " | ") {
+				if field := chocoInfoFieldRegexp.FindStringSubmatch(" " + strings.TrimSpace(part)); field != nil {
+					key = field[1]
+					if value := strings.TrimSpace(field[2]); value != "" {
+						fields[key] = append(fields[key], value)
+					}
+				}
+			}
+			continue
+		}
+
+		// Continuation lines belong to the field above, other lines end it
+		if key != "" && (line == "" || strings.HasPrefix(line, " ")) {
+			if line = strings.TrimSpace(line); line != "" {
+				fields[key] = append(fields[key], line)
+			}
+		} else {
+			key = ""
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no package details found")
+	}
+
+	details := &PackageDetails{
+		Homepage:     strings.Join(fields["Software Site"], " "),
+		License:      strings.Join(fields["Software License"], " "),
+		Description:  strings.Join(fields["Description"], "\n"),
+		ReleaseNotes: strings.Join(fields["Release Notes"], "\n"),
+		Tags:         strings.Fields(strings.Join(fields["Tags"], " ")),
+	}
+	if authors, ok := fields["Authors"]; ok {
+		details.Publisher = strings.Join(authors, " ")
+	} else {
+		details.Publisher = strings.Join(fields["Author"], " ")
+	}
+	if details.Description == "" {
+		details.Description = strings.Join(fields["Summary"], "\n")
+	}
+	return details, nil
+}
+
+type ScoopManager struct{ command backendCommand }
+
+func (s *ScoopManager) Name() string {
+	return "scoop"
+}
+
+func (s *ScoopManager) DisplayName() string {
+	return "Scoop"
+}
+
+func (s *ScoopManager) Executable() string {
+	return s.command.executable("scoop")
+}
+
+func (s *ScoopManager) SetExecutable(path string) {
+	s.command.setExecutable(path)
+}
+
+func (s *ScoopManager) Version(ctx context.Context) (string, error) {
+	return commandVersion(ctx, s.commands(), s.Executable())
+}
+
+func (s *ScoopManager) SetRunner(runner CommandRunner) {
+	s.command.setRunner(runner)
+}
+
+func (s *ScoopManager) commands() CommandRunner {
+	return s.command.commands()
+}
+
+func (s *ScoopManager) Search(ctx context.Context, query string) ([]*AppInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	output, err := s.commands().Output(ctx, s.Executable(), "search", query)
+	if err != nil {
+		return nil, fmt.Errorf("scoop search failed: %w", commandError(ctx, err))
+	}
+
+	return parseScoopSearchOutput(string(output))
+}
+
+// Install installs a "bucket/app" package, adding the bucket first when it is
+// not known locally yet. Plain app names are resolved by Scoop itself.
+func (s *ScoopManager) Install(ctx context.Context, packageID string, options InstallOptions, progress ProgressFunc) error {
+	if bucket, _ := splitScoopPackageID(packageID); bucket != "" {
+		if err := s.ensureBucket(ctx, bucket); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	args, err := scoopInstallArgs(packageID, options)
+	if err != nil {
+		return err
+	}
+
+	err = runWithProgress(ctx, s.commands(), parseScoopProgressLine, progress, s.Executable(), args...)
+	return packageError(ctx, s.Name(), "install", packageID, err, nil)
+}
+
+// scoopArchitectures maps install architectures to the names scoop uses
+var scoopArchitectures = map[string]string{
+	"x64":   "64bit",
+	"x86":   "32bit",
+	"arm64": "arm64",
+}
+
+func scoopInstallArgs(packageID string, options InstallOptions) ([]string, error) {
+	// Scoop apps are portable, there is no installer to pass arguments to
+	if options.Location != "" || options.Override != "" || options.CustomArgs != "" {
+		return nil, fmt.Errorf("scoop can't change the install location or installer arguments of %s", packageID)
+	}
+
+	if options.Version != "" {
+		// Scoop generates a manifest for the requested version on the fly
+		packageID += "@" + options.Version
+	}
+	args := []string{"install", packageID}
+	if options.Scope == "machine" {
+		args = append(args, "--global")
+	}
+	if options.Architecture != "" {
+		arch, ok := scoopArchitectures[options.Architecture]
+		if !ok {
+			return nil, fmt.Errorf("scoop can't install %s for %s", packageID, options.Architecture)
+		}
+		args = append(args, "--arch", arch)
+	}
+	return args, nil
+}
+
+// Pin holds the app at its installed version. Scoop can't hold a version
+// that isn't installed, so any other version fails with
+// ErrPinVersionUnsupported.
+func (s *ScoopManager) Pin(ctx context.Context, packageID, version string) error {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	_, name := splitScoopPackageID(packageID)
+	if version != "" {
+		installed, err := s.GetInstalledApps(ctx)
+		if err != nil {
+			return err
+		}
+		installedVersion := ""
+		for _, app := range installed {
+			if app.Name == name {
+				installedVersion = app.Version
+				break
+			}
+		}
+		if installedVersion == "" {
+			return fmt.Errorf("scoop can't hold %s at %s, it is not installed: %w", name, version, ErrPinVersionUnsupported)
+		}
+		if installedVersion != version {
+			return fmt.Errorf("scoop can't hold %s at %s, %s is installed: %w", name, version, installedVersion, ErrPinVersionUnsupported)
+		}
+	}
+	if err := s.commands().Run(ctx, nil, s.Executable(), "hold", name); err != nil {
+		return fmt.Errorf("scoop hold failed: %w", commandError(ctx, err))
+	}
+	return nil
+}
+
+func (s *ScoopManager) Unpin(ctx context.Context, packageID string) error {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	_, name := splitScoopPackageID(packageID)
+	if err := s.commands().Run(ctx, nil, s.Executable(), "unhold", name); err != nil {
+		return fmt.Errorf("scoop unhold failed: %w", commandError(ctx, err))
+	}
+	return nil
+}
+
+func (s *ScoopManager) Uninstall(ctx context.Context, packageID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	_, name := splitScoopPackageID(packageID)
+	err := s.commands().Run(ctx, nil, s.Executable(), "uninstall", name)
+	return packageError(ctx, s.Name(), "uninstall", packageID, err, nil)
+}
+
+func (s *ScoopManager) Upgrade(ctx context.Context, packageID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	_, name := splitScoopPackageID(packageID)
+	err := s.commands().Run(ctx, nil, s.Executable(), "update", name)
+	return packageError(ctx, s.Name(), "upgrade", packageID, err, nil)
+}
+
+func (s *ScoopManager) GetInstalledApps(ctx context.Context) ([]*AppInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	output, err := s.commands().Output(ctx, s.Executable(), "list")
+	if err != nil {
+		return nil, fmt.Errorf("scoop list failed: %w", commandError(ctx, err))
+	}
+
+	return parseScoopListOutput(string(output))
+}
+
+// GetUpgradableApps runs "scoop status", which only reports app names, and
+// resolves each app's bucket from the installed list so IDs match "scoop list"
+func (s *ScoopManager) GetUpgradableApps(ctx context.Context) ([]*AppInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	output, err := s.commands().Output(ctx, s.Executable(), "status")
+	if err != nil {
+		return nil, fmt.Errorf("scoop status failed: %w", commandError(ctx, err))
+	}
+
+	apps, err := parseScoopStatusOutput(string(output))
+	if err != nil || len(apps) == 0 {
+		return apps, err
+	}
+
+	installed, err := s.GetInstalledApps(ctx)
+	if err != nil {
+		return nil, err
+	}
+	installedIDs := make(map[string]string)
+	for _, app := range installed {
+		installedIDs[app.Name] = app.PackageID
+	}
+	for _, app := range apps {
+		if packageID, ok := installedIDs[app.Name]; ok {
+			app.PackageID = packageID
+		}
+	}
+
+	return apps, nil
+}
+
+func (s *ScoopManager) Details(ctx context.Context, packageID string) (*PackageDetails, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	// scoop info accepts bucket/app as well as a bare app name
+	output, err := s.commands().Output(ctx, s.Executable(), "info", packageID)
+	if err != nil {
+		return nil, fmt.Errorf("scoop info failed: %w", commandError(ctx, err))
+	}
+
+	return parseScoopInfoOutput(string(output))
+}
+
+// Buckets returns the names of the buckets added to the local Scoop install
+func (s *ScoopManager) Buckets(ctx context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	output, err := s.commands().Output(ctx, s.Executable(), "bucket", "list")
+	if err != nil {
+		return nil, fmt.Errorf("scoop bucket list failed: %w", commandError(ctx, err))
+	}
+
+	return parseScoopBucketListOutput(string(output)), nil
+}
+
+func (s *ScoopManager) ensureBucket(ctx context.Context, bucket string) error {
+	buckets, err := s.Buckets(ctx)
+	if err != nil {
+		return err
+	}
+	for _, known := range buckets {
+		if strings.EqualFold(known, bucket) {
+			return nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	if err := s.commands().Run(ctx, nil, s.Executable(), "bucket", "add", bucket); err != nil {
+		return fmt.Errorf("failed to add scoop bucket '%s': %w", bucket, commandError(ctx, err))
+	}
+	return nil
+}
+
+// splitScoopPackageID splits "bucket/app" into its parts. IDs without a bucket
+// return an empty bucket.
+func splitScoopPackageID(packageID string) (bucket, name string) {
+	if idx := strings.Index(packageID, "/"); idx >= 0 {
+		return packageID[:idx], packageID[idx+1:]
+	}
+	return "", packageID
+}
+
+var (
+	scoopLegacyBucketRegex = regexp.MustCompile(`^'([^']+)' bucket`)
+	scoopLegacySearchRegex = regexp.MustCompile(`^\s+(\S+) \(([^)]*)\)`)
+	scoopLegacyListRegex   = regexp.MustCompile(`^\s+(\S+) (\S+)(?: \*global\*)?(?: \[([^\]]+)\])?`)
+)
+
+// parseScoopSearchOutput handles both the table printed by Scoop 0.3+ and the
+// older "'bucket' bucket:" grouped format
+func parseScoopSearchOutput(output string) ([]*AppInfo, error) {
+	var apps []*AppInfo
+
+	if rows := parseScoopTable(output); rows != nil {
+		for _, row := range rows {
+			if row["Name"] == "" {
+				continue
+			}
+			apps = append(apps, newScoopApp(row["Name"], row["Version"], row["Source"]))
+		}
+		return apps, nil
+	}
+
+	bucket := ""
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r", ""), "\n") {
+		if match := scoopLegacyBucketRegex.FindStringSubmatch(line); match != nil {
+			bucket = match[1]
+			continue
+		}
+		if match := scoopLegacySearchRegex.FindStringSubmatch(line); match != nil {
+			apps = append(apps, newScoopApp(match[1], match[2], bucket))
+		}
+	}
+
+	return apps, nil
+}
+
+// parseScoopListOutput parses "scoop list" in both table and legacy formats
+func parseScoopListOutput(output string) ([]*AppInfo, error) {
+	var apps []*AppInfo
+
+	if rows := parseScoopTable(output); rows != nil {
+		for _, row := range rows {
+			if row["Name"] == "" {
+				continue
+			}
+			apps = append(apps, newScoopApp(row["Name"], row["Version"], row["Source"]))
+		}
+	} else {
+		for _, line := range strings.Split(strings.ReplaceAll(output, "\r", ""), "\n") {
+			if match := scoopLegacyListRegex.FindStringSubmatch(line); match != nil {
+				apps = append(apps, newScoopApp(match[1], match[2], match[3]))
+			}
+		}
+	}
+
+	// Mark all as installed
+	for _, app := range apps {
+		app.IsInstalled = true
+		app.InstalledVersion = app.Version
+	}
+
+	return apps, nil
+}
+
+// parseScoopStatusOutput parses the outdated apps table printed by "scoop status".
+// Rows without a latest version (e.g. removed manifests) are skipped.
+func parseScoopStatusOutput(output string) ([]*AppInfo, error) {
+	var apps []*AppInfo
+
+	for _, row := range parseScoopTable(output) {
+		if row["Name"] == "" || row["Latest Version"] == "" {
+			continue
+		}
+		app := newScoopApp(row["Name"], row["Installed Version"], "")
+		app.IsInstalled = true
+		app.InstalledVersion = app.Version
+		app.AvailableVersion = row["Latest Version"]
+		apps = append(apps, app)
+	}
+
+	return apps, nil
+}
+
+// parseScoopBucketListOutput returns bucket names from "scoop bucket list",
+// which older Scoop versions print as one name per line
+func parseScoopBucketListOutput(output string) []string {
+	var buckets []string
+
+	if rows := parseScoopTable(output); rows != nil {
+		for _, row := range rows {
+			if row["Name"] != "" {
+				buckets = append(buckets, row["Name"])
+			}
+		}
+		return buckets
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r", ""), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.Contains(line, " ") {
+			buckets = append(buckets, line)
+		}
+	}
+	return buckets
+}
+
+func newScoopApp(name, version, bucket string) *AppInfo {
+	packageID := name
+	if bucket != "" {
+		packageID = bucket + "/" + name
+	}
+	return &AppInfo{
+		Name:      name,
+		PackageID: packageID,
+		Version:   version,
+		Source:    "scoop",
+	}
+}
+
+// parseScoopTable parses the PowerShell Format-Table output Scoop prints. Column
+// boundaries come from the dash groups under the header, so values separated by
+// a single space are still split correctly. Returns nil when no table is found.
+func parseScoopTable(output string) []map[string]string {
+	lines := strings.Split(strings.ReplaceAll(output, "\r", ""), "\n")
+
+	var headers []string
+	var starts []int
+	var rows []map[string]string
+
+	for i, line := range lines {
+		if starts == nil {
+			if i > 0 && isDashLine(line) {
+				starts = dashGroupStarts(line)
+				headers = splitColumns(lines[i-1], starts)
+				rows = make([]map[string]string, 0)
+			}
+			continue
+		}
+
+		// The table ends at the first blank line after its rows
+		if strings.TrimSpace(line) == "" {
+			if len(rows) > 0 {
+				break
+			}
+			continue
+		}
+
+		cells := splitColumns(line, starts)
+		row := make(map[string]string, len(headers))
+		for j, header := range headers {
+			row[header] = cells[j]
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+func isDashLine(line string) bool {
+	line = strings.TrimSpace(line)
+	return line != "" && strings.Trim(line, "- ") == ""
+}
+
+func dashGroupStarts(line string) []int {
+	var starts []int
+	runes := []rune(line)
+	for i, r := range runes {
+		if r == '-' && (i == 0 || runes[i-1] == ' ') {
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
+
+// splitColumns cuts a line at the given rune offsets and trims each cell
+func splitColumns(line string, starts []int) []string {
+	runes := []rune(line)
+	cells := make([]string, len(starts))
+	for i, start := range starts {
+		if start >= len(runes) {
+			continue
+		}
+		end := len(runes)
+		if i+1 < len(starts) && starts[i+1] < end {
+			end = starts[i+1]
+		}
+		cells[i] = strings.TrimSpace(string(runes[start:end]))
+	}
+	return cells
+}
+
+// parseScoopInfoOutput reads the "Key : value" list printed by "scoop info"
+func parseScoopInfoOutput(output string) (*PackageDetails, error) {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		name, value, found := strings.Cut(strings.TrimRight(line, "\r "), " : ")
+		if !found || strings.HasPrefix(name, " ") {
+			continue
+		}
+		fields[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no package details found")
+	}
+
+	return &PackageDetails{
+		Homepage:    fields["Website"],
+		License:     fields["License"],
+		Description: fields["Description"],
+	}, nil
 }
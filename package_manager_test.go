@@ -0,0 +1,527 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func readFixture(t *testing.T, parts ...string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(append([]string{"testdata"}, parts...)...))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	return string(data)
+}
+
+// appSummary flattens the fields the parsers are responsible for
+type appSummary struct {
+	Name             string
+	PackageID        string
+	Version          string
+	AvailableVersion string
+	Repository       string
+	Match            string
+	IsInstalled      bool
+}
+
+func summarize(apps []*AppInfo) []appSummary {
+	result := make([]appSummary, len(apps))
+	for i, app := range apps {
+		result[i] = appSummary{
+			Name:             app.Name,
+			PackageID:        app.PackageID,
+			Version:          app.Version,
+			AvailableVersion: app.AvailableVersion,
+			Repository:       app.Repository,
+			Match:            app.Match,
+			IsInstalled:      app.IsInstalled,
+		}
+	}
+	return result
+}
+
+func TestParseWingetOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		parse   func(string) ([]*AppInfo, error)
+		want    []appSummary
+	}{
+		{
+			name:    "search",
+			fixture: "search.txt",
+			parse:   parseWingetSearchOutput,
+			want: []appSummary{
+				{Name: "Notepad++", PackageID: "Notepad++.Notepad++", Version: "8.6.0", Repository: "winget"},
+				{Name: "Notepad3", PackageID: "Rizonesoft.Notepad3", Version: "6.23.203.2", Repository: "winget"},
+				{Name: "Notepad Next", PackageID: "dail8859.NotepadNext", Version: "v0.7", Repository: "winget"},
+				{Name: "Windows Notepad", PackageID: "9MSMLRH6LZF3", Version: "Unknown", Repository: "msstore"},
+			},
+		},
+		{
+			name:    "search with match column",
+			fixture: "search_match.txt",
+			parse:   parseWingetSearchOutput,
+			want: []appSummary{
+				{Name: "Visual Studio Code", PackageID: "Microsoft.VisualStudioCode", Version: "1.85.1", Repository: "winget", Match: "Moniker: vscode"},
+				{Name: "Visual Studio Code  Insiders", PackageID: "Microsoft.VisualStudioCode.Insiders", Version: "1.86.0", Repository: "winget", Match: "Tag: vscode"},
+				{Name: "VSCodium", PackageID: "VSCodium.VSCodium", Version: "1.85.1.23348", Repository: "winget", Match: "Tag: vscode"},
+				{Name: "Cursor", PackageID: "Anysphere.Cursor", Repository: "winget", Match: "Tag: vscode"},
+			},
+		},
+		{
+			name:    "truncated search",
+			fixture: "search_truncated.txt",
+			parse:   parseWingetSearchOutput,
+			want: []appSummary{
+				{Name: "Microsoft Visual C++ 2015-2022 Redistributable (x…", PackageID: "Microsoft.VCRedist.2015+.x…", Version: "14.38.33130.0", Repository: "winget"},
+				{Name: "Microsoft Visual C++ 2013 Redistributable (x64)", PackageID: "Microsoft.VCRedist.2013.x64", Version: "12.0.40664.0", Repository: "winget"},
+			},
+		},
+		{
+			name:    "wide characters",
+			fixture: "search_cjk.txt",
+			parse:   parseWingetSearchOutput,
+			want: []appSummary{
+				{Name: "百度网盘", PackageID: "Baidu.BaiduNetdisk", Version: "7.37.5.3", Repository: "winget"},
+				{Name: "カカオトーク", PackageID: "Kakao.KakaoTalk", Version: "4.1.0.3512", Repository: "winget"},
+				{Name: "WPS Office", PackageID: "Kingsoft.WPSOffice", Version: "12.2.0.13359", Repository: "winget"},
+			},
+		},
+		{
+			name:    "no results",
+			fixture: "search_empty.txt",
+			parse:   parseWingetSearchOutput,
+			want:    []appSummary{},
+		},
+		{
+			name:    "list",
+			fixture: "list.txt",
+			parse:   parseWingetListOutput,
+			want: []appSummary{
+				{Name: "7-Zip 23.01 (x64)", PackageID: "7zip.7zip", Version: "23.01", Repository: "winget", IsInstalled: true},
+				{Name: "Git", PackageID: "Git.Git", Version: "2.42.0", AvailableVersion: "2.43.0", Repository: "winget", IsInstalled: true},
+				{Name: "Microsoft Edge", PackageID: "Microsoft.Edge", Version: "120.0.2210.91", Repository: "winget", IsInstalled: true},
+				{Name: "Mozilla Maintenance Service", PackageID: `ARP\Machine\X64\MozillaMaintenanceService`, IsInstalled: true},
+				{Name: "Microsoft  Teams classic", PackageID: "Microsoft.Teams.Classic", Version: "1.6.00.29964", IsInstalled: true},
+				{Name: "Windows Terminal", PackageID: "Microsoft.WindowsTerminal", Version: "1.18.3181.0", Repository: "winget", IsInstalled: true},
+			},
+		},
+		{
+			name:    "upgrade",
+			fixture: "upgrade.txt",
+			parse:   parseWingetUpgradeOutput,
+			want: []appSummary{
+				{Name: "Git", PackageID: "Git.Git", Version: "2.42.0", AvailableVersion: "2.43.0", Repository: "winget", IsInstalled: true},
+				{Name: "Node.js LTS", PackageID: "OpenJS.NodeJS.LTS", Version: "20.10.0", AvailableVersion: "20.11.0", Repository: "winget", IsInstalled: true},
+				{Name: "Microsoft Visual C++ 2015-2022 Redistributable (x…", PackageID: "Microsoft.VCRedist.2015+.x…", Version: "14.36.32532.0", AvailableVersion: "14.38.33130.0", Repository: "winget", IsInstalled: true},
+				{Name: "Discord", PackageID: "Discord.Discord", Version: "1.0.9028", AvailableVersion: "1.0.9030", Repository: "winget", IsInstalled: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apps, err := tt.parse(readFixture(t, "winget", tt.fixture))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := summarize(apps); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+			for _, app := range apps {
+				if app.Source != "winget" {
+					t.Errorf("%s: source = %q, want winget", app.Name, app.Source)
+				}
+			}
+		})
+	}
+}
+
+func TestParseWingetOutputLocales(t *testing.T) {
+	search := func(tag string) []appSummary {
+		return []appSummary{
+			{Name: "Visual Studio Code", PackageID: "Microsoft.VisualStudioCode", Version: "1.85.1", Repository: "winget", Match: "Moniker: vscode"},
+			{Name: "VSCodium", PackageID: "VSCodium.VSCodium", Version: "1.85.1.23348", Repository: "winget", Match: tag + ": vscode"},
+		}
+	}
+	list := []appSummary{
+		{Name: "Git", PackageID: "Git.Git", Version: "2.42.0", AvailableVersion: "2.43.0", Repository: "winget", IsInstalled: true},
+		{Name: "7-Zip 23.01 (x64)", PackageID: "7zip.7zip", Version: "23.01", Repository: "winget", IsInstalled: true},
+	}
+	upgrade := list[:1]
+
+	tests := []struct {
+		fixture string
+		parse   func(string) ([]*AppInfo, error)
+		want    []appSummary
+	}{
+		{"de_search.txt", parseWingetSearchOutput, search("Tag")},
+		{"fr_search.txt", parseWingetSearchOutput, search("Balise")},
+		{"ja_search.txt", parseWingetSearchOutput, search("タグ")},
+		{"de_list.txt", parseWingetListOutput, list},
+		{"fr_list.txt", parseWingetListOutput, list},
+		{"ja_list.txt", parseWingetListOutput, list},
+		{"de_upgrade.txt", parseWingetUpgradeOutput, upgrade},
+		{"fr_upgrade.txt", parseWingetUpgradeOutput, upgrade},
+		{"ja_upgrade.txt", parseWingetUpgradeOutput, upgrade},
+		{"de_search_source.txt", parseWingetSearchOutput, []appSummary{
+			{Name: "Notepad++", PackageID: "Notepad++.Notepad++", Version: "8.6.0", Repository: "winget"},
+			{Name: "Windows-Editor", PackageID: "9MSMLRH6LZF3", Version: "Unknown", Repository: "msstore"},
+		}},
+		{"fr_list_available.txt", parseWingetListOutput, []appSummary{
+			{Name: "Git", PackageID: "Git.Git", Version: "2.42.0", AvailableVersion: "2.43.0", IsInstalled: true},
+			{Name: "Microsoft Edge", PackageID: "Microsoft.Edge", Version: "120.0.2210.91", IsInstalled: true},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			apps, err := tt.parse(readFixture(t, "winget", "locales", tt.fixture))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := summarize(apps); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchTruncatedApp(t *testing.T) {
+	app := &AppInfo{Name: "Microsoft Visual C++ 2015-2022 Redistributable (x…", PackageID: "Microsoft.VCRedist.2015+.x…", Repository: "winget"}
+	candidates := []*AppInfo{
+		{Name: "Microsoft Visual C++ 2015-2022 Redistributable (x64)", PackageID: "Microsoft.VCRedist.2015+.x64", Repository: "winget"},
+		{Name: "Microsoft Visual C++ 2015-2022 Redistributable (x86)", PackageID: "Microsoft.VCRedist.2015+.x86", Repository: "winget"},
+	}
+
+	// Both candidates fit the visible prefix, so the ID stays unresolved
+	if match := matchTruncatedApp(app, candidates); match != nil {
+		t.Errorf("ambiguous candidates resolved to %s", match.PackageID)
+	}
+
+	if match := matchTruncatedApp(app, candidates[:1]); match == nil || match.PackageID != "Microsoft.VCRedist.2015+.x64" {
+		t.Errorf("got %+v, want Microsoft.VCRedist.2015+.x64", match)
+	}
+
+	other := &AppInfo{Name: "Something Else", PackageID: "Microsoft.VCRedist.2015+.x64"}
+	if match := matchTruncatedApp(app, []*AppInfo{other}); match != nil {
+		t.Errorf("candidate with a different name resolved to %s", match.PackageID)
+	}
+}
+
+func TestParseScoopOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		parse   func(string) ([]*AppInfo, error)
+		want    []appSummary
+	}{
+		{
+			name:    "search table",
+			fixture: "search.txt",
+			parse:   parseScoopSearchOutput,
+			want: []appSummary{
+				{Name: "git", PackageID: "main/git", Version: "2.43.0"},
+				{Name: "git-annex", PackageID: "main/git-annex", Version: "10.20231129"},
+				{Name: "git-lfs", PackageID: "main/git-lfs", Version: "3.4.0"},
+				{Name: "gitkraken", PackageID: "extras/gitkraken", Version: "9.10.0"},
+				{Name: "posh-git", PackageID: "extras/posh-git", Version: "1.1.0"},
+			},
+		},
+		{
+			name:    "legacy search",
+			fixture: "search_legacy.txt",
+			parse:   parseScoopSearchOutput,
+			want: []appSummary{
+				{Name: "git", PackageID: "main/git", Version: "2.43.0"},
+				{Name: "git-lfs", PackageID: "main/git-lfs", Version: "3.4.0"},
+				{Name: "gitkraken", PackageID: "extras/gitkraken", Version: "9.10.0"},
+			},
+		},
+		{
+			name:    "list table",
+			fixture: "list.txt",
+			parse:   parseScoopListOutput,
+			want: []appSummary{
+				{Name: "7zip", PackageID: "main/7zip", Version: "23.01", IsInstalled: true},
+				{Name: "git", PackageID: "main/git", Version: "2.43.0", IsInstalled: true},
+				{Name: "nodejs", PackageID: "main/nodejs", Version: "21.4.0", IsInstalled: true},
+				{Name: "vscode", PackageID: "extras/vscode", Version: "1.85.0", IsInstalled: true},
+			},
+		},
+		{
+			name:    "legacy list",
+			fixture: "list_legacy.txt",
+			parse:   parseScoopListOutput,
+			want: []appSummary{
+				{Name: "7zip", PackageID: "main/7zip", Version: "23.01", IsInstalled: true},
+				{Name: "git", PackageID: "main/git", Version: "2.43.0", IsInstalled: true},
+				{Name: "nodejs", PackageID: "main/nodejs", Version: "21.4.0", IsInstalled: true},
+			},
+		},
+		{
+			name:    "status",
+			fixture: "status.txt",
+			parse:   parseScoopStatusOutput,
+			want: []appSummary{
+				{Name: "git", PackageID: "git", Version: "2.42.0", AvailableVersion: "2.43.0", IsInstalled: true},
+				{Name: "nodejs", PackageID: "nodejs", Version: "21.3.0", AvailableVersion: "21.4.0", IsInstalled: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apps, err := tt.parse(readFixture(t, "scoop", tt.fixture))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := summarize(apps); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+			for _, app := range apps {
+				if app.Source != "scoop" {
+					t.Errorf("%s: source = %q, want scoop", app.Name, app.Source)
+				}
+			}
+		})
+	}
+}
+
+func TestParseScoopBucketListOutput(t *testing.T) {
+	got := parseScoopBucketListOutput(readFixture(t, "scoop", "bucket_list.txt"))
+	want := []string{"extras", "main"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	legacy := parseScoopBucketListOutput("extras\r\nmain\r\n")
+	if !reflect.DeepEqual(legacy, want) {
+		t.Errorf("legacy: got %v, want %v", legacy, want)
+	}
+}
+
+func TestSplitScoopPackageID(t *testing.T) {
+	tests := []struct {
+		packageID  string
+		wantBucket string
+		wantName   string
+	}{
+		{"main/git", "main", "git"},
+		{"extras/vscode", "extras", "vscode"},
+		{"git", "", "git"},
+	}
+
+	for _, tt := range tests {
+		bucket, name := splitScoopPackageID(tt.packageID)
+		if bucket != tt.wantBucket || name != tt.wantName {
+			t.Errorf("splitScoopPackageID(%q) = %q, %q; want %q, %q", tt.packageID, bucket, name, tt.wantBucket, tt.wantName)
+		}
+	}
+}
+
+func TestParseDetailsOutput(t *testing.T) {
+	vscode := &PackageDetails{
+		Publisher:    "Microsoft Corporation",
+		Homepage:     "https://code.visualstudio.com/",
+		License:      "Microsoft Software License",
+		Description:  "Visual Studio Code is a lightweight but powerful source code editor which runs on your desktop and is available for Windows, macOS and Linux.",
+		Tags:         []string{"code", "developer-tools", "editor"},
+		ReleaseNotes: "Welcome to the November 2023 release of Visual Studio Code.\n- Floating editor windows",
+	}
+
+	tests := []struct {
+		name    string
+		fixture []string
+		parse   func(string) (*PackageDetails, error)
+		want    *PackageDetails
+	}{
+		{
+			name:    "winget show",
+			fixture: []string{"winget", "show.txt"},
+			parse:   parseWingetShowOutput,
+			want:    vscode,
+		},
+		{
+			// Every field is labelled in German, so each one needs its German label
+			name:    "winget show in German",
+			fixture: []string{"winget", "locales", "de_show.txt"},
+			parse:   parseWingetShowOutput,
+			want:    vscode,
+		},
+		{
+			name:    "winget show in Japanese",
+			fixture: []string{"winget", "locales", "ja_show.txt"},
+			parse:   parseWingetShowOutput,
+			want:    vscode,
+		},
+		{
+			name:    "choco info",
+			fixture: []string{"choco", "info.txt"},
+			parse:   parseChocoInfoOutput,
+			want: &PackageDetails{
+				Homepage:     "https://www.7-zip.org/",
+				License:      "http://www.7-zip.org/license.txt",
+				Description:  "7-Zip is a file archiver with a high compression ratio.\n## Features\n- High compression ratio in 7z format with LZMA and LZMA2 compression",
+				Tags:         []string{"7zip", "zip", "archiver", "admin", "foss"},
+				ReleaseNotes: "http://www.7-zip.org/history.txt",
+			},
+		},
+		{
+			name:    "scoop info",
+			fixture: []string{"scoop", "info.txt"},
+			parse:   parseScoopInfoOutput,
+			want: &PackageDetails{
+				Homepage:    "https://gitforwindows.org",
+				License:     "GPL-2.0-only",
+				Description: "Distributed version control system",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(readFixture(t, tt.fixture...))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Labels in a language the parser doesn't know must not come back as empty details
+func TestParseWingetShowUnknownLanguage(t *testing.T) {
+	output := "Encontrado Visual Studio Code [Microsoft.VisualStudioCode]\r\nVersão: 1.85.1\r\nFornecedor: Microsoft Corporation\r\n"
+	if details, err := parseWingetShowOutput(output); err == nil {
+		t.Errorf("got %+v, want an error", details)
+	}
+}
+
+func TestInstallArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func(string, InstallOptions) ([]string, error)
+		id      string
+		options InstallOptions
+		want    []string // nil when the options are rejected
+	}{
+		{
+			name:  "winget defaults",
+			build: wingetInstallArgs,
+			id:    "Git.Git",
+			want:  []string{"install", "--id", "Git.Git", "--exact", "--silent", "--accept-source-agreements", "--accept-package-agreements"},
+		},
+		{
+			name:    "winget version",
+			build:   wingetInstallArgs,
+			id:      "Git.Git",
+			options: InstallOptions{Version: "2.42.0"},
+			want:    []string{"install", "--id", "Git.Git", "--exact", "--version", "2.42.0", "--silent", "--accept-source-agreements", "--accept-package-agreements"},
+		},
+		{
+			name:    "winget all options",
+			build:   wingetInstallArgs,
+			id:      "Git.Git",
+			options: InstallOptions{Scope: "user", Architecture: "arm64", Location: `D:\Tools\Git`, Override: "/VERYSILENT", CustomArgs: "/NORESTART", Interactive: true},
+			want: []string{"install", "--id", "Git.Git", "--exact", "--scope", "user", "--architecture", "arm64",
+				"--location", `D:\Tools\Git`, "--override", "/VERYSILENT", "--custom", "/NORESTART", "--interactive",
+				"--accept-source-agreements", "--accept-package-agreements"},
+		},
+		{
+			name:    "choco version",
+			build:   chocoInstallArgs,
+			id:      "git",
+			options: InstallOptions{Version: "2.42.0"},
+			want:    []string{"install", "git", "-y", "--version", "2.42.0", "--allow-downgrade"},
+		},
+		{
+			name:    "choco x86 with override",
+			build:   chocoInstallArgs,
+			id:      "git",
+			options: InstallOptions{Architecture: "x86", Override: "/VERYSILENT", Interactive: true},
+			want:    []string{"install", "git", "-y", "--forcex86", "--install-arguments", "/VERYSILENT", "--override-arguments", "--not-silent"},
+		},
+		{
+			name:    "choco custom args",
+			build:   chocoInstallArgs,
+			id:      "git",
+			options: InstallOptions{Scope: "machine", CustomArgs: "/NORESTART"},
+			want:    []string{"install", "git", "-y", "--install-arguments", "/NORESTART"},
+		},
+		{
+			name:    "choco per user",
+			build:   chocoInstallArgs,
+			id:      "git",
+			options: InstallOptions{Scope: "user"},
+		},
+		{
+			name:    "choco location",
+			build:   chocoInstallArgs,
+			id:      "git",
+			options: InstallOptions{Location: `D:\Tools`},
+		},
+		{
+			name:    "scoop version",
+			build:   scoopInstallArgs,
+			id:      "main/git",
+			options: InstallOptions{Version: "2.42.0"},
+			want:    []string{"install", "main/git@2.42.0"},
+		},
+		{
+			name:    "scoop global x86",
+			build:   scoopInstallArgs,
+			id:      "main/git",
+			options: InstallOptions{Scope: "machine", Architecture: "x86"},
+			want:    []string{"install", "main/git", "--global", "--arch", "32bit"},
+		},
+		{
+			name:    "scoop custom args",
+			build:   scoopInstallArgs,
+			id:      "main/git",
+			options: InstallOptions{CustomArgs: "/NORESTART"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.build(tt.id, tt.options)
+			if tt.want == nil {
+				if err == nil {
+					t.Errorf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVersionOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{"winget", "v1.7.10861\r\n", "1.7.10861"},
+		{"choco", "2.2.2\r\n", "2.2.2"},
+		{"scoop", "Current Scoop version:\nv0.4.2 - Released at 2024-05-14\n\n'main' bucket:\n1a2b3c4 (HEAD -> master) main@2024-06-01\n", "0.4.2"},
+		{"no version", "Current Scoop version:\n6d9d1d4 (HEAD -> master)\n", "Current Scoop version:"},
+	}
+	for _, tt := range tests {
+		if got := parseVersionOutput(tt.output); got != tt.want {
+			t.Errorf("%s: parseVersionOutput = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
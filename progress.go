@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ProgressPhase is the stage an installation is in, as reported by the backend
+type ProgressPhase string
+
+const (
+	PhaseStarting    ProgressPhase = "Starting"
+	PhaseDownloading ProgressPhase = "Downloading"
+	PhaseVerifying   ProgressPhase = "Verifying"
+	PhaseInstalling  ProgressPhase = "Installing"
+	PhaseDone        ProgressPhase = "Done"
+	PhaseFailed      ProgressPhase = "Failed"
+	PhaseCancelled   ProgressPhase = "Cancelled"
+	PhaseRestart     ProgressPhase = "Restart required" // Installed, but only complete after a restart
+)
+
+// finished reports whether the installation has ended in this phase
+func (p ProgressPhase) finished() bool {
+	return p == PhaseDone || p == PhaseFailed || p == PhaseCancelled || p == PhaseRestart
+}
+
+// ProgressEvent describes one step of a running installation
+type ProgressEvent struct {
+	Phase   ProgressPhase
+	Percent float64 // Progress of the current phase from 0 to 1, or -1 when unknown
+	Line    string  // Output line to append to the log, empty for progress bar updates
+}
+
+// ProgressFunc receives progress events while a package manager command runs.
+// It is called from the goroutine reading the command output.
+type ProgressFunc func(event ProgressEvent)
+
+// progressParser inspects a single output line. It returns the phase the line
+// starts (empty if none) and the percentage it reports (-1 if none).
+type progressParser func(line string) (ProgressPhase, float64)
+
+var (
+	percentRegexp = regexp.MustCompile(`(\d{1,3}(?:\.\d+)?)\s*%`)
+	// winget draws "  ███▒▒▒  1.50 MB / 4.45 MB" while downloading
+	wingetSizeRegexp = regexp.MustCompile(`([\d.]+)\s*(B|KB|MB|GB)\s*/\s*([\d.]+)\s*(B|KB|MB|GB)`)
+)
+
+func parseWingetProgressLine(line string) (ProgressPhase, float64) {
+	switch {
+	case strings.HasPrefix(line, "Downloading "):
+		return PhaseDownloading, -1
+	case strings.Contains(line, "verified installer hash"):
+		return PhaseVerifying, -1
+	case strings.HasPrefix(line, "Starting package install"):
+		return PhaseInstalling, -1
+	case strings.HasPrefix(line, "Successfully installed"):
+		return PhaseDone, -1
+	}
+
+	if match := wingetSizeRegexp.FindStringSubmatch(line); match != nil {
+		done := parseSize(match[1], match[2])
+		total := parseSize(match[3], match[4])
+		if total > 0 {
+			return "", done / total
+		}
+	}
+	if isProgressBar(line) {
+		if match := percentRegexp.FindStringSubmatch(line); match != nil {
+			return "", parsePercent(match[1])
+		}
+	}
+	return "", -1
+}
+
+func parseChocoProgressLine(line string) (ProgressPhase, float64) {
+	if strings.HasPrefix(line, "Progress:") {
+		if match := percentRegexp.FindStringSubmatch(line); match != nil {
+			return PhaseDownloading, parsePercent(match[1])
+		}
+		return PhaseDownloading, -1
+	}
+
+	switch {
+	case strings.HasPrefix(line, "Downloading "):
+		return PhaseDownloading, -1
+	case strings.HasPrefix(line, "Hashes match"):
+		return PhaseVerifying, -1
+	case strings.HasPrefix(line, "Installing the following packages"):
+		return "", -1
+	case strings.HasPrefix(line, "Installing "):
+		return PhaseInstalling, -1
+	case strings.HasSuffix(line, "has been installed."),
+		strings.HasPrefix(line, "The install of") && strings.HasSuffix(line, "was successful."):
+		return PhaseDone, -1
+	}
+	return "", -1
+}
+
+func parseScoopProgressLine(line string) (ProgressPhase, float64) {
+	switch {
+	case strings.HasPrefix(line, "Downloading "), strings.HasPrefix(line, "Starting download"):
+		return PhaseDownloading, -1
+	case strings.HasPrefix(line, "Checking hash of"):
+		return PhaseVerifying, -1
+	case strings.HasPrefix(line, "Installing '"):
+		return PhaseInstalling, -1
+	case strings.Contains(line, "was installed successfully"):
+		return PhaseDone, -1
+	}
+	return "", -1
+}
+
+// isProgressBar reports whether the line is one of winget's block-drawn progress bars
+func isProgressBar(line string) bool {
+	return strings.ContainsAny(line, "█▒")
+}
+
+func parsePercent(value string) float64 {
+	percent, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return -1
+	}
+	return clampProgress(percent / 100)
+}
+
+func parseSize(value, unit string) float64 {
+	size, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	switch unit {
+	case "KB":
+		size *= 1 << 10
+	case "MB":
+		size *= 1 << 20
+	case "GB":
+		size *= 1 << 30
+	}
+	return size
+}
+
+func clampProgress(value float64) float64 {
+	if value < 0 {
+		return 0
+	}
+	if value > 1 {
+		return 1
+	}
+	return value
+}
+
+// cleanProgressLine strips the backspaces and spinner characters winget uses
+// to animate its console output
+func cleanProgressLine(line string) string {
+	if i := strings.LastIndexByte(line, '\b'); i >= 0 {
+		line = line[i+1:]
+	}
+	line = strings.TrimSpace(line)
+	if line == "-" || line == "\\" || line == "|" || line == "/" {
+		return ""
+	}
+	return line
+}
+
+// scanProgressLines splits output on both \n and \r, since progress bars are
+// redrawn in place with carriage returns
+func scanProgressLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// readProgress parses command output from r and reports it through progress.
+// Progress bar redraws only produce an event when the whole percentage changes.
+func readProgress(r io.Reader, parse progressParser, progress ProgressFunc) {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(scanProgressLines)
+
+	current := ProgressEvent{Phase: PhaseStarting, Percent: -1}
+	progress(current)
+
+	for scanner.Scan() {
+		line := cleanProgressLine(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		phase, percent := parse(line)
+		event := current
+		event.Line = line
+
+		// Percentages within the current phase are progress bar redraws,
+		// which are kept out of the log
+		isRedraw := percent >= 0 && (phase == "" || phase == current.Phase)
+
+		if phase != "" && phase != current.Phase {
+			event.Phase = phase
+			event.Percent = -1
+			if phase == PhaseDone {
+				event.Percent = 1
+			}
+		}
+		if percent >= 0 {
+			event.Percent = percent
+		}
+		if isRedraw {
+			event.Line = ""
+			if int(percent*100) == int(current.Percent*100) {
+				continue
+			}
+		}
+
+		current = event
+		current.Line = ""
+		progress(event)
+	}
+}
+
+// runWithProgress runs a command through runner and streams its combined
+// output to progress line by line. Without a progress func the command just runs.
+func runWithProgress(ctx context.Context, runner CommandRunner, parse progressParser, progress ProgressFunc, name string, args ...string) error {
+	if progress == nil {
+		return runner.Run(ctx, nil, name, args...)
+	}
+
+	reader, writer := io.Pipe()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		readProgress(reader, parse, progress)
+		// Drain anything left if the parser stopped early
+		io.Copy(io.Discard, reader)
+	}()
+
+	err := runner.Run(ctx, writer, name, args...)
+	writer.Close()
+	<-done
+	return err
+}
+
+// maxProgressLogLines caps how much output is kept per installation
+const maxProgressLogLines = 500
+
+// InstallProgress is the latest known state of an installation, kept so the
+// UI can render it again when list rows are recycled
+type InstallProgress struct {
+	Phase   ProgressPhase
+	Percent float64
+	Log     []string
+}
+
+func (p *InstallProgress) apply(event ProgressEvent) {
+	p.Phase = event.Phase
+	p.Percent = event.Percent
+	if event.Line != "" {
+		p.Log = append(p.Log, event.Line)
+		if len(p.Log) > maxProgressLogLines {
+			p.Log = p.Log[len(p.Log)-maxProgressLogLines:]
+		}
+	}
+}
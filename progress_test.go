@@ -0,0 +1,99 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// progressStep flattens an event so percentages compare reliably
+type progressStep struct {
+	Phase   ProgressPhase
+	Percent int
+	Line    string
+}
+
+func collectProgress(t *testing.T, output string, parse progressParser) []progressStep {
+	t.Helper()
+	var steps []progressStep
+	readProgress(strings.NewReader(output), parse, func(event ProgressEvent) {
+		percent := -1
+		if event.Percent >= 0 {
+			percent = int(event.Percent * 100)
+		}
+		steps = append(steps, progressStep{event.Phase, percent, event.Line})
+	})
+	return steps
+}
+
+func TestReadWingetProgress(t *testing.T) {
+	got := collectProgress(t, readFixture(t, "winget", "install.txt"), parseWingetProgressLine)
+	want := []progressStep{
+		{PhaseStarting, -1, ""},
+		{PhaseStarting, -1, "Found Notepad++ [Notepad++.Notepad++] Version 8.6.0"},
+		{PhaseStarting, -1, "This application is licensed to you by its owner."},
+		{PhaseStarting, -1, "Microsoft is not responsible for, nor does it grant any licenses to, third-party packages."},
+		{PhaseDownloading, -1, "Downloading https://github.com/notepad-plus-plus/notepad-plus-plus/releases/download/v8.6/npp.8.6.Installer.x64.exe"},
+		{PhaseDownloading, 0, ""},
+		{PhaseDownloading, 22, ""},
+		{PhaseDownloading, 50, ""},
+		{PhaseDownloading, 100, ""},
+		{PhaseVerifying, -1, "Successfully verified installer hash"},
+		{PhaseInstalling, -1, "Starting package install..."},
+		{PhaseDone, 100, "Successfully installed"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestReadChocoProgress(t *testing.T) {
+	got := collectProgress(t, readFixture(t, "choco", "install.txt"), parseChocoProgressLine)
+	want := []progressStep{
+		{PhaseStarting, -1, ""},
+		{PhaseStarting, -1, "Chocolatey v2.2.2"},
+		{PhaseStarting, -1, "Installing the following packages:"},
+		{PhaseStarting, -1, "7zip"},
+		{PhaseStarting, -1, "By installing, you accept licenses for the packages."},
+		{PhaseDownloading, 10, "Progress: Downloading 7zip.install 23.1.0... 10%"},
+		{PhaseDownloading, 55, ""},
+		{PhaseDownloading, 100, ""},
+		{PhaseDownloading, 100, "7zip.install v23.1.0 [Approved]"},
+		{PhaseDownloading, 100, "7zip.install package files install completed. Performing other installation steps."},
+		{PhaseVerifying, -1, "Hashes match."},
+		{PhaseInstalling, -1, "Installing 7zip.install..."},
+		{PhaseDone, 100, "7zip.install has been installed."},
+		{PhaseDone, 100, "7zip.install can be automatically uninstalled."},
+		{PhaseDone, 100, "The install of 7zip.install was successful."},
+		{PhaseDone, 100, "Software installed to 'C:\\Program Files\\7-Zip\\'"},
+		{PhaseDone, 100, "Chocolatey installed 2/2 packages."},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseProgressPercentages(t *testing.T) {
+	tests := []struct {
+		line  string
+		parse progressParser
+		want  int
+	}{
+		{"███████▒▒▒▒▒▒▒▒  1.00 MB / 2.00 MB", parseWingetProgressLine, 50},
+		{"██████████████▒  512 KB / 1.00 MB", parseWingetProgressLine, 50},
+		{"████████▒▒▒▒▒▒▒  53%", parseWingetProgressLine, 53},
+		{"Progress: 75% - Saving 1.5 MB of 2 MB", parseChocoProgressLine, 75},
+		{"Found Notepad++ 100% free", parseWingetProgressLine, -1},
+	}
+
+	for _, tt := range tests {
+		_, percent := tt.parse(tt.line)
+		got := -1
+		if percent >= 0 {
+			got = int(percent*100 + 0.5)
+		}
+		if got != tt.want {
+			t.Errorf("%q: got %d%%, want %d%%", tt.line, got, tt.want)
+		}
+	}
+}
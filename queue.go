@@ -0,0 +1,442 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// JobState is where an install job is in the queue
+type JobState string
+
+const (
+	JobQueued    JobState = "Queued"
+	JobPaused    JobState = "Paused" // Held in the queue until resumed
+	JobRunning   JobState = "Running"
+	JobSucceeded JobState = "Succeeded"
+	JobFailed    JobState = "Failed"
+	JobCancelled JobState = "Cancelled"
+)
+
+// finished reports whether a job in this state will not run again unless retried
+func (s JobState) finished() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobCancelled
+}
+
+// defaultInstallConcurrency is how many installs run at once per backend.
+// winget in particular misbehaves when several installs race each other.
+const defaultInstallConcurrency = 1
+
+// maxInstallConcurrency caps the per backend concurrency settings offer
+const maxInstallConcurrency = 4
+
+// InstallJob is one installation waiting in or run by the InstallQueue
+type InstallJob struct {
+	ID         int
+	App        *AppInfo
+	State      JobState
+	Err        error // Result of the last run, soft failures included
+	QueuedAt   time.Time
+	StartedAt  time.Time // Zero until the job runs
+	FinishedAt time.Time
+
+	cancel context.CancelFunc // Cancels the running install
+	done   chan struct{}      // Closed once the job has finished
+}
+
+// installFunc installs a single app. The AppManager queue runs
+// AppManager.runInstall.
+type installFunc func(ctx context.Context, app *AppInfo) error
+
+// InstallQueue runs install jobs in order while limiting how many run at
+// once for each backend. Jobs can be reordered, paused, cancelled and retried.
+type InstallQueue struct {
+	ctx     context.Context // Cancelling it cancels every job
+	install installFunc
+
+	mutex   sync.Mutex
+	jobs    []*InstallJob
+	nextID  int
+	limits  map[string]int // Concurrency per source, defaultInstallConcurrency when missing
+	running map[string]int // Running jobs per source
+	paused  bool           // No new jobs start while paused
+
+	onChange func() // Called outside the lock whenever jobs change state
+}
+
+func NewInstallQueue(ctx context.Context, install installFunc) *InstallQueue {
+	return &InstallQueue{
+		ctx:     ctx,
+		install: install,
+		nextID:  1,
+		limits:  make(map[string]int),
+		running: make(map[string]int),
+	}
+}
+
+// SetOnChange registers the function told about job state changes
+func (q *InstallQueue) SetOnChange(onChange func()) {
+	q.mutex.Lock()
+	q.onChange = onChange
+	q.mutex.Unlock()
+}
+
+func (q *InstallQueue) Concurrency(source string) int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.concurrency(source)
+}
+
+// concurrency assumes the mutex is already locked
+func (q *InstallQueue) concurrency(source string) int {
+	if limit, ok := q.limits[source]; ok {
+		return limit
+	}
+	return defaultInstallConcurrency
+}
+
+// SetConcurrency changes how many installs of source may run at once
+func (q *InstallQueue) SetConcurrency(source string, limit int) {
+	if limit < 1 {
+		limit = 1
+	}
+	if limit > maxInstallConcurrency {
+		limit = maxInstallConcurrency
+	}
+	q.mutex.Lock()
+	q.limits[source] = limit
+	updates := q.schedule()
+	q.mutex.Unlock()
+	q.notify(updates)
+}
+
+// Enqueue adds an install of app to the end of the queue and returns the job
+// ID. An app that is already waiting or installing keeps its existing job.
+func (q *InstallQueue) Enqueue(app *AppInfo) int {
+	id, _ := q.enqueue(app)
+	return id
+}
+
+// enqueue is Enqueue that also reports whether a new job was created
+func (q *InstallQueue) enqueue(app *AppInfo) (int, bool) {
+	q.mutex.Lock()
+	if job := q.activeJob(app); job != nil {
+		q.mutex.Unlock()
+		return job.ID, false
+	}
+
+	job := &InstallJob{
+		ID:       q.nextID,
+		App:      app,
+		State:    JobQueued,
+		QueuedAt: time.Now(),
+		done:     make(chan struct{}),
+	}
+	q.nextID++
+	q.jobs = append(q.jobs, job)
+
+	updates := append([]InstallJob{*job}, q.schedule()...)
+	q.mutex.Unlock()
+	q.notify(updates)
+	return job.ID, true
+}
+
+// activeJob returns the unfinished job installing app. This method assumes
+// the mutex is already locked.
+func (q *InstallQueue) activeJob(app *AppInfo) *InstallJob {
+	for _, job := range q.jobs {
+		if !job.State.finished() && job.App.Source == app.Source && job.App.PackageID == app.PackageID {
+			return job
+		}
+	}
+	return nil
+}
+
+func (q *InstallQueue) find(id int) *InstallJob {
+	for _, job := range q.jobs {
+		if job.ID == id {
+			return job
+		}
+	}
+	return nil
+}
+
+// Jobs returns a snapshot of every job in queue order
+func (q *InstallQueue) Jobs() []InstallJob {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	jobs := make([]InstallJob, 0, len(q.jobs))
+	for _, job := range q.jobs {
+		jobs = append(jobs, *job)
+	}
+	return jobs
+}
+
+// ActiveJob returns a snapshot of the queued, paused or running job for app
+func (q *InstallQueue) ActiveJob(app *AppInfo) (InstallJob, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if job := q.activeJob(app); job != nil {
+		return *job, true
+	}
+	return InstallJob{}, false
+}
+
+// Wait blocks until the job has finished and returns its error. It returns
+// early with ctx's error when ctx is done first.
+func (q *InstallQueue) Wait(ctx context.Context, id int) error {
+	q.mutex.Lock()
+	job := q.find(id)
+	if job == nil {
+		q.mutex.Unlock()
+		return fmt.Errorf("no install job %d", id)
+	}
+	done := job.done
+	q.mutex.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return job.Err
+}
+
+// Cancel removes a waiting job from the queue or stops a running one
+func (q *InstallQueue) Cancel(id int) bool {
+	return q.cancelJob(id, true)
+}
+
+// cancelWaiting cancels the job only if it has not started yet
+func (q *InstallQueue) cancelWaiting(id int) bool {
+	return q.cancelJob(id, false)
+}
+
+func (q *InstallQueue) cancelJob(id int, stopRunning bool) bool {
+	q.mutex.Lock()
+	job := q.find(id)
+	if job == nil {
+		q.mutex.Unlock()
+		return false
+	}
+
+	var updates []InstallJob
+	switch job.State {
+	case JobQueued, JobPaused:
+		q.finish(job, JobCancelled, fmt.Errorf("installation of %s was cancelled: %w", job.App.Name, context.Canceled))
+		updates = append(updates, *job)
+	case JobRunning:
+		if !stopRunning {
+			q.mutex.Unlock()
+			return false
+		}
+		// The running goroutine records the outcome once the install returns
+		job.cancel()
+	default:
+		q.mutex.Unlock()
+		return false
+	}
+	q.mutex.Unlock()
+	q.notify(updates)
+	return true
+}
+
+// Pause holds a waiting job in the queue until it is resumed
+func (q *InstallQueue) Pause(id int) bool {
+	return q.setWaitingState(id, JobQueued, JobPaused)
+}
+
+// Resume lets a paused job run again once its turn comes
+func (q *InstallQueue) Resume(id int) bool {
+	return q.setWaitingState(id, JobPaused, JobQueued)
+}
+
+func (q *InstallQueue) setWaitingState(id int, from, to JobState) bool {
+	q.mutex.Lock()
+	job := q.find(id)
+	if job == nil || job.State != from {
+		q.mutex.Unlock()
+		return false
+	}
+	job.State = to
+	updates := append([]InstallJob{*job}, q.schedule()...)
+	q.mutex.Unlock()
+	q.notify(updates)
+	return true
+}
+
+// SetPaused stops or restarts the whole queue. Running jobs are not
+// interrupted, but no new ones start while the queue is paused.
+func (q *InstallQueue) SetPaused(paused bool) {
+	q.mutex.Lock()
+	q.paused = paused
+	updates := q.schedule()
+	q.mutex.Unlock()
+	q.notify(updates)
+}
+
+func (q *InstallQueue) IsPaused() bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.paused
+}
+
+// Retry queues a failed or cancelled job again, at the end of the queue
+func (q *InstallQueue) Retry(id int) bool {
+	q.mutex.Lock()
+	job := q.find(id)
+	if job == nil || (job.State != JobFailed && job.State != JobCancelled) || q.activeJob(job.App) != nil {
+		q.mutex.Unlock()
+		return false
+	}
+
+	for i, queued := range q.jobs {
+		if queued == job {
+			q.jobs = append(q.jobs[:i], q.jobs[i+1:]...)
+			break
+		}
+	}
+	job.State = JobQueued
+	job.Err = nil
+	job.QueuedAt = time.Now()
+	job.StartedAt = time.Time{}
+	job.FinishedAt = time.Time{}
+	job.done = make(chan struct{})
+	q.jobs = append(q.jobs, job)
+
+	updates := append([]InstallJob{*job}, q.schedule()...)
+	q.mutex.Unlock()
+	q.notify(updates)
+	return true
+}
+
+// Move shifts a job by offset places in the queue, e.g. -1 to run it earlier.
+// Only the order of jobs that have not started matters.
+func (q *InstallQueue) Move(id int, offset int) bool {
+	q.mutex.Lock()
+	from := -1
+	for i, job := range q.jobs {
+		if job.ID == id {
+			from = i
+		}
+	}
+	to := from + offset
+	if from < 0 || to < 0 || to >= len(q.jobs) || offset == 0 {
+		q.mutex.Unlock()
+		return false
+	}
+
+	job := q.jobs[from]
+	q.jobs = append(q.jobs[:from], q.jobs[from+1:]...)
+	q.jobs = append(q.jobs[:to], append([]*InstallJob{job}, q.jobs[to:]...)...)
+
+	updates := append([]InstallJob{*job}, q.schedule()...)
+	q.mutex.Unlock()
+	q.notify(updates)
+	return true
+}
+
+// ClearFinished removes succeeded, failed and cancelled jobs from the queue
+func (q *InstallQueue) ClearFinished() {
+	q.mutex.Lock()
+	jobs := q.jobs[:0]
+	for _, job := range q.jobs {
+		if !job.State.finished() {
+			jobs = append(jobs, job)
+		}
+	}
+	q.jobs = jobs
+	q.mutex.Unlock()
+}
+
+// schedule starts every queued job whose backend has a free slot, in queue
+// order. This method assumes the mutex is already locked and returns the jobs
+// it changed so the caller can report them once the lock is released.
+func (q *InstallQueue) schedule() []InstallJob {
+	var updates []InstallJob
+	if q.ctx.Err() != nil {
+		// Shutting down, nothing waiting will run anymore
+		for _, job := range q.jobs {
+			if job.State == JobQueued || job.State == JobPaused {
+				q.finish(job, JobCancelled, fmt.Errorf("installation of %s was cancelled: %w", job.App.Name, q.ctx.Err()))
+				updates = append(updates, *job)
+			}
+		}
+		return updates
+	}
+	if q.paused {
+		return nil
+	}
+
+	for _, job := range q.jobs {
+		source := job.App.Source
+		if job.State != JobQueued || q.running[source] >= q.concurrency(source) {
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(q.ctx)
+		job.cancel = cancel
+		job.State = JobRunning
+		job.StartedAt = time.Now()
+		q.running[source]++
+		updates = append(updates, *job)
+
+		go q.run(ctx, job)
+	}
+	return updates
+}
+
+func (q *InstallQueue) run(ctx context.Context, job *InstallJob) {
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("installation of %s stopped unexpectedly: %v", job.App.Name, r)
+			}
+		}()
+		err = q.install(ctx, job.App)
+	}()
+
+	q.mutex.Lock()
+	state := JobSucceeded
+	switch {
+	case errors.Is(err, context.Canceled):
+		state = JobCancelled
+	case err != nil && !IsSoftFailure(err):
+		state = JobFailed
+	}
+	job.cancel()
+	q.running[job.App.Source]--
+	q.finish(job, state, err)
+
+	updates := append([]InstallJob{*job}, q.schedule()...)
+	q.mutex.Unlock()
+	q.notify(updates)
+}
+
+// finish ends a job and wakes up its waiters. This method assumes the mutex
+// is already locked.
+func (q *InstallQueue) finish(job *InstallJob, state JobState, err error) {
+	job.State = state
+	job.Err = err
+	job.FinishedAt = time.Now()
+	close(job.done)
+}
+
+// notify reports the changed jobs to onChange, once per batch of changes
+func (q *InstallQueue) notify(updates []InstallJob) {
+	q.mutex.Lock()
+	onChange := q.onChange
+	q.mutex.Unlock()
+
+	if onChange != nil && len(updates) > 0 {
+		onChange()
+	}
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingInstaller installs an app once the test releases it with a result
+type blockingInstaller struct {
+	mutex   sync.Mutex
+	release map[string]chan error
+	started chan string
+}
+
+func newBlockingInstaller() *blockingInstaller {
+	return &blockingInstaller{release: make(map[string]chan error), started: make(chan string, 16)}
+}
+
+func (b *blockingInstaller) channel(packageID string) chan error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if _, ok := b.release[packageID]; !ok {
+		b.release[packageID] = make(chan error, 1)
+	}
+	return b.release[packageID]
+}
+
+func (b *blockingInstaller) install(ctx context.Context, app *AppInfo) error {
+	b.started <- app.PackageID
+	select {
+	case err := <-b.channel(app.PackageID):
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *blockingInstaller) finish(packageID string, err error) {
+	b.channel(packageID) <- err
+}
+
+// expectStarted waits for the given installs to start, in any order
+func (b *blockingInstaller) expectStarted(t *testing.T, want ...string) {
+	t.Helper()
+	pending := make(map[string]bool)
+	for _, packageID := range want {
+		pending[packageID] = true
+	}
+	for len(pending) > 0 {
+		select {
+		case got := <-b.started:
+			if !pending[got] {
+				t.Fatalf("started %s, want %v", got, want)
+			}
+			delete(pending, got)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%v did not start", pending)
+		}
+	}
+}
+
+func (b *blockingInstaller) expectIdle(t *testing.T) {
+	t.Helper()
+	select {
+	case got := <-b.started:
+		t.Fatalf("unexpected start of %s", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func queueStates(q *InstallQueue) map[string]JobState {
+	states := make(map[string]JobState)
+	for _, job := range q.Jobs() {
+		states[job.App.PackageID] = job.State
+	}
+	return states
+}
+
+func TestInstallQueueConcurrencyPerBackend(t *testing.T) {
+	installer := newBlockingInstaller()
+	queue := NewInstallQueue(context.Background(), installer.install)
+
+	gitID := queue.Enqueue(&AppInfo{Name: "Git", PackageID: "Git.Git", Source: "winget"})
+	vimID := queue.Enqueue(&AppInfo{Name: "Vim", PackageID: "vim.vim", Source: "winget"})
+	nodeID := queue.Enqueue(&AppInfo{Name: "Node.js", PackageID: "nodejs", Source: "choco"})
+
+	// winget runs one install at a time, choco has its own slot
+	installer.expectStarted(t, "Git.Git", "nodejs")
+	installer.expectIdle(t)
+
+	if again := queue.Enqueue(&AppInfo{Name: "Git", PackageID: "Git.Git", Source: "winget"}); again != gitID {
+		t.Errorf("queueing a running app again created job %d, want %d", again, gitID)
+	}
+
+	installer.finish("Git.Git", nil)
+	if err := queue.Wait(context.Background(), gitID); err != nil {
+		t.Fatalf("Git failed: %v", err)
+	}
+	installer.expectStarted(t, "vim.vim")
+
+	installer.finish("vim.vim", errors.New("exit status 1"))
+	if err := queue.Wait(context.Background(), vimID); err == nil {
+		t.Fatal("expected Vim to fail")
+	}
+	installer.finish("nodejs", &PackageError{Kind: ErrAlreadyInstalled, Err: errors.New("exit status 1")})
+	queue.Wait(context.Background(), nodeID)
+
+	states := queueStates(queue)
+	if states["Git.Git"] != JobSucceeded || states["vim.vim"] != JobFailed || states["nodejs"] != JobSucceeded {
+		t.Errorf("unexpected states %v", states)
+	}
+
+	queue.ClearFinished()
+	if jobs := queue.Jobs(); len(jobs) != 0 {
+		t.Errorf("%d jobs left after ClearFinished", len(jobs))
+	}
+}
+
+func TestInstallQueueSetConcurrency(t *testing.T) {
+	installer := newBlockingInstaller()
+	queue := NewInstallQueue(context.Background(), installer.install)
+	queue.SetPaused(true)
+
+	queue.Enqueue(&AppInfo{PackageID: "a", Source: "scoop"})
+	queue.Enqueue(&AppInfo{PackageID: "b", Source: "scoop"})
+	queue.Enqueue(&AppInfo{PackageID: "c", Source: "scoop"})
+	installer.expectIdle(t)
+
+	queue.SetConcurrency("scoop", 2)
+	queue.SetPaused(false)
+	installer.expectStarted(t, "a", "b")
+	installer.expectIdle(t)
+
+	installer.finish("a", nil)
+	installer.expectStarted(t, "c")
+	installer.finish("b", nil)
+	installer.finish("c", nil)
+
+	if got := queue.Concurrency("winget"); got != defaultInstallConcurrency {
+		t.Errorf("default concurrency = %d", got)
+	}
+	queue.SetConcurrency("winget", 99)
+	if got := queue.Concurrency("winget"); got != maxInstallConcurrency {
+		t.Errorf("concurrency was not capped: %d", got)
+	}
+}
+
+func TestInstallQueueReorderPauseCancelRetry(t *testing.T) {
+	installer := newBlockingInstaller()
+	queue := NewInstallQueue(context.Background(), installer.install)
+
+	first := queue.Enqueue(&AppInfo{Name: "First", PackageID: "first", Source: "winget"})
+	installer.expectStarted(t, "first")
+	second := queue.Enqueue(&AppInfo{Name: "Second", PackageID: "second", Source: "winget"})
+	third := queue.Enqueue(&AppInfo{Name: "Third", PackageID: "third", Source: "winget"})
+	fourth := queue.Enqueue(&AppInfo{Name: "Fourth", PackageID: "fourth", Source: "winget"})
+
+	// Run the fourth job before the others and hold the third
+	if !queue.Move(fourth, -2) {
+		t.Fatal("Move failed")
+	}
+	if !queue.Pause(third) {
+		t.Fatal("Pause failed")
+	}
+	if !queue.Cancel(second) {
+		t.Fatal("Cancel of a queued job failed")
+	}
+	if err := queue.Wait(context.Background(), second); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled job returned %v", err)
+	}
+
+	// Cancelling the running job lets the next one start
+	queue.Cancel(first)
+	if err := queue.Wait(context.Background(), first); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled running job returned %v", err)
+	}
+	installer.expectStarted(t, "fourth")
+	installer.finish("fourth", nil)
+	queue.Wait(context.Background(), fourth)
+	installer.expectIdle(t)
+
+	if states := queueStates(queue); states["third"] != JobPaused || states["first"] != JobCancelled {
+		t.Errorf("unexpected states %v", states)
+	}
+
+	if !queue.Resume(third) {
+		t.Fatal("Resume failed")
+	}
+	installer.expectStarted(t, "third")
+	installer.finish("third", nil)
+	queue.Wait(context.Background(), third)
+
+	if !queue.Retry(first) {
+		t.Fatal("Retry of a cancelled job failed")
+	}
+	installer.expectStarted(t, "first")
+	installer.finish("first", nil)
+	if err := queue.Wait(context.Background(), first); err != nil {
+		t.Errorf("retried job failed: %v", err)
+	}
+	if queue.Retry(first) {
+		t.Error("a succeeded job was retried")
+	}
+
+	jobs := queue.Jobs()
+	if jobs[len(jobs)-1].ID != first {
+		t.Errorf("retried job is not last in the queue")
+	}
+}
+
+func TestInstallQueueShutdown(t *testing.T) {
+	installer := newBlockingInstaller()
+	ctx, cancel := context.WithCancel(context.Background())
+	queue := NewInstallQueue(ctx, installer.install)
+
+	running := queue.Enqueue(&AppInfo{PackageID: "running", Source: "winget"})
+	installer.expectStarted(t, "running")
+	waiting := queue.Enqueue(&AppInfo{PackageID: "waiting", Source: "winget"})
+
+	cancel()
+	if err := queue.Wait(context.Background(), running); !errors.Is(err, context.Canceled) {
+		t.Errorf("running job returned %v", err)
+	}
+	if err := queue.Wait(context.Background(), waiting); !errors.Is(err, context.Canceled) {
+		t.Errorf("waiting job returned %v", err)
+	}
+	installer.expectIdle(t)
+}
+
+func TestBatchCancelStopsRunningInstalls(t *testing.T) {
+	installer := newBlockingInstaller()
+	queue := NewInstallQueue(context.Background(), installer.install)
+
+	apps := []*AppInfo{
+		{Name: "Git", PackageID: "Git.Git", Source: "winget"},
+		{Name: "Node.js", PackageID: "nodejs", Source: "choco"},
+		{Name: "Vim", PackageID: "vim", Source: "scoop"},
+		{Name: "7-Zip", PackageID: "7zip.7zip", Source: "winget"},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	reports := make(chan *BatchReport)
+	go func() { reports <- runBatch(ctx, queue, apps, BatchOptions{}) }()
+
+	// One install per backend runs, 7-Zip waits behind Git
+	installer.expectStarted(t, "Git.Git", "nodejs", "vim")
+	installer.finish("nodejs", nil)
+	deadline := time.Now().Add(2 * time.Second)
+	for queueStates(queue)["nodejs"] != JobSucceeded {
+		if time.Now().After(deadline) {
+			t.Fatal("Node.js did not finish")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Cancelled while the batch waits for Git, with Vim still running
+	cancel()
+	var report *BatchReport
+	select {
+	case report = <-reports:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the cancelled batch did not return")
+	}
+	installer.expectIdle(t)
+
+	states := queueStates(queue)
+	for packageID, state := range states {
+		if !state.finished() {
+			t.Errorf("%s is still %s after the batch returned", packageID, state)
+		}
+	}
+	want := []BatchOutcome{BatchSkipped, BatchInstalled, BatchSkipped, BatchSkipped}
+	for i, result := range report.Results {
+		if result.Outcome != want[i] {
+			t.Errorf("%s: outcome %s, want %s", result.Name, result.Outcome, want[i])
+		}
+	}
+	if states["vim"] != JobCancelled {
+		t.Errorf("Vim ended %s, want cancelled", states["vim"])
+	}
+	if reason := report.Results[3].Reason; reason != "the batch was cancelled" {
+		t.Errorf("7-Zip skipped because %q", reason)
+	}
+}
+
+func TestBatchCancelKeepsInstallsItDidNotQueue(t *testing.T) {
+	installer := newBlockingInstaller()
+	queue := NewInstallQueue(context.Background(), installer.install)
+
+	// Git was started from its row before the batch
+	git := &AppInfo{Name: "Git", PackageID: "Git.Git", Source: "winget"}
+	gitID := queue.Enqueue(git)
+	installer.expectStarted(t, "Git.Git")
+
+	apps := []*AppInfo{git, {Name: "Node.js", PackageID: "nodejs", Source: "choco"}}
+	ctx, cancel := context.WithCancel(context.Background())
+	reports := make(chan *BatchReport)
+	go func() { reports <- runBatch(ctx, queue, apps, BatchOptions{}) }()
+	installer.expectStarted(t, "nodejs")
+
+	cancel()
+	report := <-reports
+	if states := queueStates(queue); states["Git.Git"] != JobRunning || states["nodejs"] != JobCancelled {
+		t.Errorf("unexpected states %v", states)
+	}
+	for _, result := range report.Results {
+		if result.Outcome != BatchSkipped {
+			t.Errorf("%s: outcome %s, want skipped", result.Name, result.Outcome)
+		}
+	}
+
+	installer.finish("Git.Git", nil)
+	if err := queue.Wait(context.Background(), gitID); err != nil {
+		t.Errorf("Git failed after the batch was cancelled: %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+)
+
+// App management functions (updated for lists)
+func SaveAppToList(db *sql.DB, listID int64, app *AppInfo) error {
+	details := app.Details
+	if details == nil {
+		details = &PackageDetails{}
+	}
+	tags, err := encodeTags(details.Tags)
+	if err != nil {
+		return err
+	}
+
+	// Saving an app again updates it in place, keeping its ID and tags. The
+	// version, pin and install options are only changed through
+	// UpdateSavedAppInstallOptions, and details are only replaced by fetched ones.
+	query := `
+	INSERT INTO saved_apps (list_id, name, package_id, version, source, description,
+		publisher, homepage, license, tags, release_notes, required_version, pinned,
+		install_scope, install_architecture, install_location, install_override, install_custom_args, install_interactive)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (list_id, source, package_id) DO UPDATE SET
+		name = excluded.name,
+		version = excluded.version,
+		description = COALESCE(NULLIF(excluded.description, ''), description),
+		publisher = COALESCE(NULLIF(excluded.publisher, ''), publisher),
+		homepage = COALESCE(NULLIF(excluded.homepage, ''), homepage),
+		license = COALESCE(NULLIF(excluded.license, ''), license),
+		tags = COALESCE(NULLIF(excluded.tags, ''), tags),
+		release_notes = COALESCE(NULLIF(excluded.release_notes, ''), release_notes)
+	`
+	options := app.Options
+	_, err = db.Exec(query, listID, app.Name, app.PackageID, app.Version, app.Source, app.Description,
+		details.Publisher, details.Homepage, details.License, tags, details.ReleaseNotes,
+		app.RequiredVersion, app.Pinned,
+		options.Scope, options.Architecture, options.Location, options.Override, options.CustomArgs, options.Interactive)
+	if err != nil {
+		return err
+	}
+
+	// Apps without tags keep the ones they already have in this list
+	if len(app.Tags) > 0 {
+		return SetSavedAppTags(db, listID, app.Source, app.PackageID, app.Tags)
+	}
+	return nil
+}
+
+// UpdateSavedAppInstallOptions sets the version a saved app is installed at,
+// whether it is pinned and the installer options to use
+func UpdateSavedAppInstallOptions(db *sql.DB, listID int64, source, packageID, requiredVersion string, pinned bool, options InstallOptions) error {
+	query := `
+	UPDATE saved_apps
+	SET required_version = ?, pinned = ?, install_scope = ?, install_architecture = ?,
+		install_location = ?, install_override = ?, install_custom_args = ?, install_interactive = ?
+	WHERE list_id = ? AND source = ? AND package_id = ?
+	`
+	_, err := db.Exec(query, requiredVersion, pinned, options.Scope, options.Architecture,
+		options.Location, options.Override, options.CustomArgs, options.Interactive,
+		listID, source, packageID)
+	return err
+}
+
+// UpdateSavedAppDetails stores fetched package details on every saved copy of the package
+func UpdateSavedAppDetails(db *sql.DB, source, packageID string, details *PackageDetails) error {
+	tags, err := encodeTags(details.Tags)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	UPDATE saved_apps
+	SET description = ?, publisher = ?, homepage = ?, license = ?, tags = ?, release_notes = ?
+	WHERE source = ? AND package_id = ?
+	`
+	_, err = db.Exec(query, details.Description, details.Publisher, details.Homepage, details.License,
+		tags, details.ReleaseNotes, source, packageID)
+	return err
+}
+
+// Tags are kept as a JSON array, since winget tags may contain spaces
+func encodeTags(tags []string) (string, error) {
+	if len(tags) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(tags)
+	return string(data), err
+}
+
+func decodeTags(value string) []string {
+	var tags []string
+	if value != "" {
+		json.Unmarshal([]byte(value), &tags)
+	}
+	return tags
+}
+
+func GetAppsInList(db *sql.DB, listID int64) ([]*AppInfo, error) {
+	query := `
+	SELECT id, name, package_id, version, source, description,
+		publisher, homepage, license, tags, release_notes, required_version, pinned,
+		install_scope, install_architecture, install_location, install_override, install_custom_args, install_interactive
+	FROM saved_apps
+	WHERE list_id = ?
+	ORDER BY name
+	`
+
+	rows, err := db.Query(query, listID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var apps []*AppInfo
+	for rows.Next() {
+		app := &AppInfo{IsSaved: true, ListID: listID}
+		details := &PackageDetails{}
+		var tags string
+		err := rows.Scan(&app.ID, &app.Name, &app.PackageID, &app.Version, &app.Source, &app.Description,
+			&details.Publisher, &details.Homepage, &details.License, &tags, &details.ReleaseNotes,
+			&app.RequiredVersion, &app.Pinned,
+			&app.Options.Scope, &app.Options.Architecture, &app.Options.Location,
+			&app.Options.Override, &app.Options.CustomArgs, &app.Options.Interactive)
+		if err != nil {
+			return nil, err
+		}
+
+		// Only apps whose details were fetched before carry them
+		details.Description = app.Description
+		details.Tags = decodeTags(tags)
+		if details.Publisher != "" || details.Homepage != "" || details.License != "" || len(details.Tags) > 0 || details.ReleaseNotes != "" {
+			app.Details = details
+		}
+		apps = append(apps, app)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tags, err := getListTags(db, listID)
+	if err != nil {
+		return nil, err
+	}
+	for _, app := range apps {
+		app.Tags = tags[int64(app.ID)]
+	}
+
+	return apps, nil
+}
+
+// RemoveAppFromList removes an app from a list. Saved apps are identified by
+// source and package ID, since the same ID can name different packages in
+// winget, Chocolatey and Scoop.
+func RemoveAppFromList(db *sql.DB, listID int64, source, packageID string) error {
+	query := `DELETE FROM saved_apps WHERE list_id = ? AND source = ? AND package_id = ?`
+	_, err := db.Exec(query, listID, source, packageID)
+	return err
+}
+
+func IsAppInList(db *sql.DB, listID int64, source, packageID string) (bool, error) {
+	query := `SELECT COUNT(*) FROM saved_apps WHERE list_id = ? AND source = ? AND package_id = ?`
+	var count int
+	err := db.QueryRow(query, listID, source, packageID).Scan(&count)
+	return count > 0, err
+}
+
+func GetAppListsContaining(db *sql.DB, source, packageID string) ([]*AppList, error) {
+	query := `
+	SELECT l.id, l.name, l.description, l.is_default, l.created_at
+	FROM lists l
+	INNER JOIN saved_apps sa ON l.id = sa.list_id
+	WHERE sa.source = ? AND sa.package_id = ?
+	ORDER BY l.name
+	`
+
+	rows, err := db.Query(query, source, packageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lists []*AppList
+	for rows.Next() {
+		list := &AppList{}
+		err := rows.Scan(&list.ID, &list.Name, &list.Description, &list.IsDefault, &list.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, list)
+	}
+
+	return lists, rows.Err()
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSaveAppToListAgainKeepsOptions(t *testing.T) {
+	db, path := openTestDB(t)
+	if err := migrateDatabase(db, path); err != nil {
+		t.Fatal(err)
+	}
+
+	git := &AppInfo{
+		Name: "Git", PackageID: "Git.Git", Version: "2.43.0", Source: "winget", Description: "Version control",
+		Details: &PackageDetails{Publisher: "The Git Development Community", License: "GPL-2.0", Tags: []string{"vcs"}},
+		Tags:    []string{"dev"},
+	}
+	if err := SaveAppToList(db, 1, git); err != nil {
+		t.Fatal(err)
+	}
+	options := InstallOptions{Scope: "machine", Location: `D:\Git`, CustomArgs: "/NORESTART", Interactive: true}
+	if err := UpdateSavedAppInstallOptions(db, 1, "winget", "Git.Git", "2.43.0", true, options); err != nil {
+		t.Fatal(err)
+	}
+
+	// Saved again from a search result, which knows nothing but the package
+	bare := &AppInfo{Name: "Git", PackageID: "Git.Git", Version: "2.44.0", Source: "winget"}
+	if err := SaveAppToList(db, 1, bare); err != nil {
+		t.Fatal(err)
+	}
+
+	apps, err := GetAppsInList(db, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(apps) != 1 {
+		t.Fatalf("got %d apps, want 1", len(apps))
+	}
+	saved := apps[0]
+	if saved.Version != "2.44.0" {
+		t.Errorf("version = %q, want the newly saved 2.44.0", saved.Version)
+	}
+	if saved.RequiredVersion != "2.43.0" || !saved.Pinned {
+		t.Errorf("required version %q pinned %v, want 2.43.0 pinned", saved.RequiredVersion, saved.Pinned)
+	}
+	if saved.Options != options {
+		t.Errorf("options = %+v, want %+v", saved.Options, options)
+	}
+	if saved.Description != "Version control" || saved.Details == nil ||
+		saved.Details.Publisher != "The Git Development Community" || saved.Details.License != "GPL-2.0" ||
+		!reflect.DeepEqual(saved.Details.Tags, []string{"vcs"}) {
+		t.Errorf("details lost: %q %+v", saved.Description, saved.Details)
+	}
+	if !reflect.DeepEqual(saved.Tags, []string{"dev"}) {
+		t.Errorf("tags = %q, want [dev]", saved.Tags)
+	}
+
+	// Newly fetched details do replace the stored ones
+	bare.Details = &PackageDetails{License: "GPL-2.0-only"}
+	if err := SaveAppToList(db, 1, bare); err != nil {
+		t.Fatal(err)
+	}
+	apps, _ = GetAppsInList(db, 1)
+	if apps[0].Details.License != "GPL-2.0-only" || apps[0].Details.Publisher != "The Git Development Community" {
+		t.Errorf("details = %+v after saving a new license", apps[0].Details)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultSearchCacheTTL is how long cached search results are served without
+// asking the backend again
+const defaultSearchCacheTTL = time.Hour
+
+// Stale results are still shown while they are refreshed, but the cache does
+// not grow forever. Searches older than searchCacheMaxAge are dropped, and
+// only the newest searchCacheMaxEntries are kept.
+const (
+	searchCacheMaxAge     = 7 * 24 * time.Hour
+	searchCacheMaxEntries = 500
+)
+
+// searchCacheTTLs are the cache lifetimes offered in settings. A zero TTL
+// turns the cache off.
+var searchCacheTTLs = []struct {
+	Label string
+	TTL   time.Duration
+}{
+	{"Off", 0},
+	{"15 minutes", 15 * time.Minute},
+	{"1 hour", time.Hour},
+	{"6 hours", 6 * time.Hour},
+	{"1 day", 24 * time.Hour},
+}
+
+// normalizeSearchQuery turns queries that differ only in case or spacing into
+// the same cache key
+func normalizeSearchQuery(query string) string {
+	return strings.ToLower(strings.Join(strings.Fields(query), " "))
+}
+
+// isSearchCacheFresh reports whether results fetched at fetchedAt can still be
+// served without refreshing them
+func isSearchCacheFresh(fetchedAt, now time.Time, ttl time.Duration) bool {
+	return ttl > 0 && now.Sub(fetchedAt) < ttl
+}
+
+// Search cache functions, keyed by backend source and normalized query
+
+// GetCachedSearch returns the cached results of a search and when they were
+// fetched. found is false when the search has never been cached.
+func GetCachedSearch(db *sql.DB, source, query string) (apps []*AppInfo, fetchedAt time.Time, found bool, err error) {
+	var results string
+	err = db.QueryRow(`SELECT results, fetched_at FROM search_cache WHERE source = ? AND query = ?`,
+		source, query).Scan(&results, &fetchedAt)
+	if err == sql.ErrNoRows {
+		return nil, time.Time{}, false, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, false, err
+	}
+
+	if err := json.Unmarshal([]byte(results), &apps); err != nil {
+		return nil, time.Time{}, false, fmt.Errorf("failed to decode cached search: %v", err)
+	}
+	return apps, fetchedAt, true, nil
+}
+
+// SaveCachedSearch stores the results of a search and drops the searches that
+// are too old or too many to keep
+func SaveCachedSearch(db *sql.DB, source, query string, apps []*AppInfo) error {
+	results, err := json.Marshal(apps)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	_, err = db.Exec(`INSERT OR REPLACE INTO search_cache (source, query, results, fetched_at) VALUES (?, ?, ?, ?)`,
+		source, query, string(results), now)
+	if err != nil {
+		return err
+	}
+	return purgeSearchCache(db, now)
+}
+
+// purgeSearchCache deletes the searches fetched longer than searchCacheMaxAge
+// before now, and the oldest ones beyond searchCacheMaxEntries
+func purgeSearchCache(db *sql.DB, now time.Time) error {
+	_, err := db.Exec(`
+		DELETE FROM search_cache
+		WHERE fetched_at < ?
+		OR rowid NOT IN (SELECT rowid FROM search_cache ORDER BY fetched_at DESC LIMIT ?)`,
+		now.Add(-searchCacheMaxAge), searchCacheMaxEntries)
+	return err
+}
+
+func ClearSearchCache(db *sql.DB) error {
+	_, err := db.Exec(`DELETE FROM search_cache`)
+	return err
+}
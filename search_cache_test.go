@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNormalizeSearchQuery(t *testing.T) {
+	tests := map[string]string{
+		"vscode":                   "vscode",
+		"  Visual   Studio\tCode ": "visual studio code",
+		"NOTEPAD++":                "notepad++",
+		"":                         "",
+	}
+	for query, want := range tests {
+		if got := normalizeSearchQuery(query); got != want {
+			t.Errorf("normalizeSearchQuery(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+func TestIsSearchCacheFresh(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		age  time.Duration
+		ttl  time.Duration
+		want bool
+	}{
+		{"within ttl", 10 * time.Minute, time.Hour, true},
+		{"expired", 2 * time.Hour, time.Hour, false},
+		{"exactly ttl", time.Hour, time.Hour, false},
+		{"cache off", 0, 0, false},
+	}
+	for _, tt := range tests {
+		if got := isSearchCacheFresh(now.Add(-tt.age), now, tt.ttl); got != tt.want {
+			t.Errorf("%s: isSearchCacheFresh = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSearchCacheStaleWhileRevalidate(t *testing.T) {
+	db, path := openTestDB(t)
+	if err := migrateDatabase(db, path); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, found, err := GetCachedSearch(db, "winget", "vscode"); err != nil || found {
+		t.Fatalf("empty cache: found %v, err %v", found, err)
+	}
+
+	old := []*AppInfo{{Name: "Visual Studio Code", PackageID: "Microsoft.VisualStudioCode", Version: "1.85.1", Source: "winget"}}
+	if err := SaveCachedSearch(db, "winget", "vscode", old); err != nil {
+		t.Fatal(err)
+	}
+	apps, fetchedAt, found, err := GetCachedSearch(db, "winget", "vscode")
+	if err != nil || !found {
+		t.Fatalf("cached search: found %v, err %v", found, err)
+	}
+	if !reflect.DeepEqual(summarize(apps), summarize(old)) || !isSearchCacheFresh(fetchedAt, time.Now(), time.Hour) {
+		t.Errorf("got %+v fetched at %v, want fresh %+v", summarize(apps), fetchedAt, summarize(old))
+	}
+
+	// Past the TTL the old results are still served, marked stale
+	if _, err := db.Exec(`UPDATE search_cache SET fetched_at = ?`, time.Now().UTC().Add(-2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	apps, fetchedAt, found, _ = GetCachedSearch(db, "winget", "vscode")
+	if !found || len(apps) != 1 || isSearchCacheFresh(fetchedAt, time.Now(), time.Hour) {
+		t.Fatalf("expired search: found %v, %d apps, fetched at %v", found, len(apps), fetchedAt)
+	}
+
+	// The background refresh replaces them
+	fresh := []*AppInfo{{Name: "Visual Studio Code", PackageID: "Microsoft.VisualStudioCode", Version: "1.86.0", Source: "winget"}}
+	if err := SaveCachedSearch(db, "winget", "vscode", fresh); err != nil {
+		t.Fatal(err)
+	}
+	apps, fetchedAt, _, _ = GetCachedSearch(db, "winget", "vscode")
+	if !reflect.DeepEqual(summarize(apps), summarize(fresh)) || !isSearchCacheFresh(fetchedAt, time.Now(), time.Hour) {
+		t.Errorf("refreshed search: got %+v fetched at %v", summarize(apps), fetchedAt)
+	}
+}
+
+func TestSearchCachePurge(t *testing.T) {
+	db, path := openTestDB(t)
+	if err := migrateDatabase(db, path); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	insert := func(query string, age time.Duration) {
+		t.Helper()
+		_, err := db.Exec(`INSERT INTO search_cache (source, query, results, fetched_at) VALUES ('winget', ?, '[]', ?)`,
+			query, now.Add(-age))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	insert("expired", searchCacheMaxAge+time.Hour)
+	for i := 0; i < searchCacheMaxEntries; i++ {
+		insert(fmt.Sprintf("query %d", i), time.Duration(i+1)*time.Minute)
+	}
+
+	if err := SaveCachedSearch(db, "winget", "newest", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM search_cache`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != searchCacheMaxEntries {
+		t.Errorf("%d cached searches, want %d", count, searchCacheMaxEntries)
+	}
+	for _, query := range []string{"expired", fmt.Sprintf("query %d", searchCacheMaxEntries-1)} {
+		if _, _, found, _ := GetCachedSearch(db, "winget", query); found {
+			t.Errorf("%q was not purged", query)
+		}
+	}
+	if _, _, found, _ := GetCachedSearch(db, "winget", "newest"); !found {
+		t.Error("the newest search was purged")
+	}
+}
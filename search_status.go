@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SearchState is how one backend's part of a search went
+type SearchState string
+
+const (
+	SearchRunning  SearchState = "Searching"
+	SearchOK       SearchState = "OK"
+	SearchFailed   SearchState = "Failed"
+	SearchTimedOut SearchState = "Timed out"
+	SearchDisabled SearchState = "Disabled"
+)
+
+// BackendSearchStatus is shown above the results so a failing backend does
+// not look like a search without results
+type BackendSearchStatus struct {
+	Source      string
+	DisplayName string
+	State       SearchState
+	Message     string // Error or reason for Failed, Timed out and Disabled
+	Results     int
+}
+
+// errBackendUnavailable is reported for enabled backends that are not installed
+var errBackendUnavailable = errors.New("not installed")
+
+// finishSearchStatus records the outcome of a backend search
+func finishSearchStatus(status *BackendSearchStatus, results int, err error) {
+	switch {
+	case err == nil:
+		status.State = SearchOK
+		status.Results = results
+		status.Message = ""
+	case errors.Is(err, errBackendUnavailable):
+		status.State = SearchDisabled
+		status.Message = err.Error()
+	case errors.Is(err, context.DeadlineExceeded):
+		status.State = SearchTimedOut
+		status.Message = fmt.Sprintf("no answer within %s", commandTimeout)
+	default:
+		status.State = SearchFailed
+		status.Message = err.Error()
+	}
+}
+
+// searchStatusSummary describes every backend's part of a search on one line
+func searchStatusSummary(statuses []BackendSearchStatus) string {
+	parts := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		var text string
+		switch status.State {
+		case SearchRunning:
+			text = "searching..."
+		case SearchOK:
+			text = fmt.Sprintf("%d results", status.Results)
+		case SearchFailed, SearchTimedOut, SearchDisabled:
+			text = strings.ToLower(string(status.State))
+			if status.Message != "" {
+				text += " (" + status.Message + ")"
+			}
+		}
+		parts = append(parts, status.DisplayName+": "+text)
+	}
+	return strings.Join(parts, " • ")
+}
+
+// searchHasProblems reports whether any backend failed or timed out
+func searchHasProblems(statuses []BackendSearchStatus) bool {
+	for _, status := range statuses {
+		if status.State == SearchFailed || status.State == SearchTimedOut {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestFinishSearchStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		state   SearchState
+		results int
+	}{
+		{"ok", nil, SearchOK, 3},
+		{"failed", errors.New("chocolatey search failed: exit status 1"), SearchFailed, 0},
+		{"not installed", errBackendUnavailable, SearchDisabled, 0},
+		{"timed out", fmt.Errorf("winget search failed: %w", context.DeadlineExceeded), SearchTimedOut, 0},
+	}
+	for _, tt := range tests {
+		status := BackendSearchStatus{Source: "winget", DisplayName: "Winget", State: SearchRunning}
+		finishSearchStatus(&status, 3, tt.err)
+		if status.State != tt.state || status.Results != tt.results {
+			t.Errorf("%s: got state %q with %d results, want %q with %d", tt.name, status.State, status.Results, tt.state, tt.results)
+		}
+		if tt.err != nil && status.Message == "" {
+			t.Errorf("%s: missing error message", tt.name)
+		}
+	}
+}
+
+func TestSearchStatusSummary(t *testing.T) {
+	statuses := []BackendSearchStatus{
+		{DisplayName: "Winget", State: SearchOK, Results: 12},
+		{DisplayName: "Chocolatey", State: SearchFailed, Message: "exit status 1"},
+		{DisplayName: "Scoop", State: SearchDisabled, Message: "not installed"},
+	}
+
+	want := "Winget: 12 results • Chocolatey: failed (exit status 1) • Scoop: disabled (not installed)"
+	if got := searchStatusSummary(statuses); got != want {
+		t.Errorf("searchStatusSummary = %q, want %q", got, want)
+	}
+	if !searchHasProblems(statuses) {
+		t.Error("searchHasProblems = false, want true")
+	}
+	if searchHasProblems(statuses[:1]) {
+		t.Error("searchHasProblems = true for a successful search")
+	}
+}
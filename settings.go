@@ -0,0 +1,230 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ThemeDark  = "dark"
+	ThemeLight = "light"
+)
+
+// Settings are the preferences kept in the settings table between launches.
+// Backend maps are keyed by source name, a missing entry means the default.
+type Settings struct {
+	Theme              string
+	SearchCacheTTL     time.Duration
+	RecordCommands     bool              // Record package manager commands to a transcript
+	EnabledBackends    map[string]bool   // Backends are enabled by default
+	ExecutablePaths    map[string]string // Executable overrides, empty for the one on PATH
+	InstallConcurrency map[string]int    // Installs running at once, defaultInstallConcurrency by default
+}
+
+func DefaultSettings() *Settings {
+	return &Settings{
+		Theme:              ThemeDark,
+		SearchCacheTTL:     defaultSearchCacheTTL,
+		EnabledBackends:    make(map[string]bool),
+		ExecutablePaths:    make(map[string]string),
+		InstallConcurrency: make(map[string]int),
+	}
+}
+
+// Clone returns a copy that can be changed without affecting s
+func (s *Settings) Clone() *Settings {
+	clone := *s
+	clone.EnabledBackends = make(map[string]bool, len(s.EnabledBackends))
+	for source, enabled := range s.EnabledBackends {
+		clone.EnabledBackends[source] = enabled
+	}
+	clone.ExecutablePaths = make(map[string]string, len(s.ExecutablePaths))
+	for source, path := range s.ExecutablePaths {
+		clone.ExecutablePaths[source] = path
+	}
+	clone.InstallConcurrency = make(map[string]int, len(s.InstallConcurrency))
+	for source, limit := range s.InstallConcurrency {
+		clone.InstallConcurrency[source] = limit
+	}
+	return &clone
+}
+
+// IsBackendEnabled reports whether source is enabled, which it is unless turned off
+func (s *Settings) IsBackendEnabled(source string) bool {
+	enabled, ok := s.EnabledBackends[source]
+	return !ok || enabled
+}
+
+// Concurrency returns how many installs of source may run at once
+func (s *Settings) Concurrency(source string) int {
+	if limit, ok := s.InstallConcurrency[source]; ok {
+		return limit
+	}
+	return defaultInstallConcurrency
+}
+
+// Validate checks the settings against the registered backend sources
+func (s *Settings) Validate(sources []string) error {
+	if s.Theme != ThemeDark && s.Theme != ThemeLight {
+		return fmt.Errorf("unknown theme %q", s.Theme)
+	}
+	if s.SearchCacheTTL < 0 {
+		return fmt.Errorf("the search cache lifetime cannot be negative")
+	}
+
+	known := make(map[string]bool)
+	enabled := 0
+	for _, source := range sources {
+		known[source] = true
+		if s.IsBackendEnabled(source) {
+			enabled++
+		}
+	}
+	if enabled == 0 {
+		return fmt.Errorf("at least one package manager must be enabled")
+	}
+	for source, limit := range s.InstallConcurrency {
+		if !known[source] {
+			return fmt.Errorf("unknown package source: %s", source)
+		}
+		if limit < 1 || limit > maxInstallConcurrency {
+			return fmt.Errorf("%s can run 1 to %d installs at once, not %d", source, maxInstallConcurrency, limit)
+		}
+	}
+	for source := range s.EnabledBackends {
+		if !known[source] {
+			return fmt.Errorf("unknown package source: %s", source)
+		}
+	}
+	for source := range s.ExecutablePaths {
+		if !known[source] {
+			return fmt.Errorf("unknown package source: %s", source)
+		}
+	}
+	return nil
+}
+
+// Keys of the settings table. Backend settings are stored as
+// "backend.<source>.<setting>".
+const (
+	settingTheme          = "theme"
+	settingSearchCacheTTL = "search_cache_ttl" // Seconds
+	settingRecordCommands = "record_commands"
+	settingBackendEnabled = "enabled"
+	settingBackendPath    = "executable"
+	settingBackendJobs    = "concurrency"
+)
+
+func backendSettingKey(source, setting string) string {
+	return "backend." + source + "." + setting
+}
+
+// LoadSettings reads the stored settings on top of the defaults. Values that
+// cannot be parsed keep their default.
+func LoadSettings(db *sql.DB) (*Settings, error) {
+	rows, err := db.Query("SELECT key, value FROM settings")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := DefaultSettings()
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		settings.set(key, value)
+	}
+	return settings, rows.Err()
+}
+
+// set applies one stored value, ignoring unknown keys and invalid values
+func (s *Settings) set(key, value string) {
+	switch key {
+	case settingTheme:
+		if value == ThemeDark || value == ThemeLight {
+			s.Theme = value
+		}
+	case settingSearchCacheTTL:
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			s.SearchCacheTTL = time.Duration(seconds) * time.Second
+		}
+	case settingRecordCommands:
+		if record, err := strconv.ParseBool(value); err == nil {
+			s.RecordCommands = record
+		}
+	}
+
+	if !strings.HasPrefix(key, "backend.") {
+		return
+	}
+	dot := strings.LastIndex(key, ".")
+	source, setting := key[len("backend."):dot], key[dot+1:]
+	if source == "" {
+		return
+	}
+	switch setting {
+	case settingBackendEnabled:
+		if enabled, err := strconv.ParseBool(value); err == nil {
+			s.EnabledBackends[source] = enabled
+		}
+	case settingBackendPath:
+		if value != "" {
+			s.ExecutablePaths[source] = value
+		}
+	case settingBackendJobs:
+		if limit, err := strconv.Atoi(value); err == nil && limit >= 1 && limit <= maxInstallConcurrency {
+			s.InstallConcurrency[source] = limit
+		}
+	}
+}
+
+// values flattens the settings into rows of the settings table
+func (s *Settings) values() map[string]string {
+	values := map[string]string{
+		settingTheme:          s.Theme,
+		settingSearchCacheTTL: strconv.Itoa(int(s.SearchCacheTTL / time.Second)),
+		settingRecordCommands: strconv.FormatBool(s.RecordCommands),
+	}
+	for source, enabled := range s.EnabledBackends {
+		values[backendSettingKey(source, settingBackendEnabled)] = strconv.FormatBool(enabled)
+	}
+	for source, path := range s.ExecutablePaths {
+		if path != "" {
+			values[backendSettingKey(source, settingBackendPath)] = path
+		}
+	}
+	for source, limit := range s.InstallConcurrency {
+		values[backendSettingKey(source, settingBackendJobs)] = strconv.Itoa(limit)
+	}
+	return values
+}
+
+// SaveSettings replaces the stored settings with settings
+func SaveSettings(db *sql.DB, settings *Settings) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM settings"); err != nil {
+		return err
+	}
+	for key, value := range settings.values() {
+		if _, err := tx.Exec("INSERT INTO settings (key, value) VALUES (?, ?)", key, value); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ResetSettings forgets every stored setting so the defaults apply again
+func ResetSettings(db *sql.DB) error {
+	_, err := db.Exec("DELETE FROM settings")
+	return err
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSources = []string{"winget", "choco", "scoop"}
+
+func TestLoadSettingsDefaults(t *testing.T) {
+	db, path := openTestDB(t)
+	if err := migrateDatabase(db, path); err != nil {
+		t.Fatal(err)
+	}
+
+	settings, err := LoadSettings(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(settings, DefaultSettings()) {
+		t.Errorf("got %+v, want the defaults", settings)
+	}
+	if err := settings.Validate(testSources); err != nil {
+		t.Errorf("defaults are invalid: %v", err)
+	}
+	if !settings.IsBackendEnabled("choco") || settings.Concurrency("winget") != defaultInstallConcurrency {
+		t.Error("backends should be enabled with the default concurrency")
+	}
+}
+
+func TestSaveAndLoadSettings(t *testing.T) {
+	db, path := openTestDB(t)
+	if err := migrateDatabase(db, path); err != nil {
+		t.Fatal(err)
+	}
+
+	settings := DefaultSettings()
+	settings.Theme = ThemeLight
+	settings.SearchCacheTTL = 6 * time.Hour
+	settings.RecordCommands = true
+	settings.EnabledBackends["choco"] = false
+	settings.ExecutablePaths["scoop"] = `D:\Tools\scoop\shims\scoop.cmd`
+	settings.InstallConcurrency["scoop"] = 3
+	if err := SaveSettings(db, settings); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadSettings(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, settings) {
+		t.Errorf("got %+v, want %+v", loaded, settings)
+	}
+
+	// Saving again replaces the stored settings
+	loaded.ExecutablePaths = map[string]string{}
+	if err := SaveSettings(db, loaded); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, _ := LoadSettings(db); len(reloaded.ExecutablePaths) != 0 {
+		t.Errorf("removed executable path came back: %v", reloaded.ExecutablePaths)
+	}
+
+	if err := ResetSettings(db); err != nil {
+		t.Fatal(err)
+	}
+	if reset, _ := LoadSettings(db); !reflect.DeepEqual(reset, DefaultSettings()) {
+		t.Errorf("got %+v after reset, want the defaults", reset)
+	}
+}
+
+func TestLoadSettingsIgnoresInvalidValues(t *testing.T) {
+	db, path := openTestDB(t)
+	if err := migrateDatabase(db, path); err != nil {
+		t.Fatal(err)
+	}
+	_, err := db.Exec(`INSERT INTO settings (key, value) VALUES
+		('theme', 'purple'),
+		('search_cache_ttl', '-5'),
+		('record_commands', 'maybe'),
+		('backend.winget.enabled', 'nope'),
+		('backend.winget.concurrency', '99'),
+		('backend.choco.concurrency', '2'),
+		('window_size', '800x600')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	settings, err := LoadSettings(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DefaultSettings()
+	want.InstallConcurrency["choco"] = 2
+	if !reflect.DeepEqual(settings, want) {
+		t.Errorf("got %+v, want %+v", settings, want)
+	}
+}
+
+func TestValidateSettings(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(settings *Settings)
+		want   string
+	}{
+		{"unknown theme", func(s *Settings) { s.Theme = "blue" }, "unknown theme"},
+		{"negative ttl", func(s *Settings) { s.SearchCacheTTL = -time.Minute }, "negative"},
+		{"every backend disabled", func(s *Settings) {
+			for _, source := range testSources {
+				s.EnabledBackends[source] = false
+			}
+		}, "at least one package manager"},
+		{"too many installs", func(s *Settings) { s.InstallConcurrency["winget"] = maxInstallConcurrency + 1 }, "installs at once"},
+		{"unknown backend", func(s *Settings) { s.ExecutablePaths["apt"] = "/usr/bin/apt" }, "unknown package source"},
+	}
+	for _, tt := range tests {
+		settings := DefaultSettings()
+		tt.change(settings)
+		err := settings.Validate(testSources)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want an error containing %q", tt.name, err, tt.want)
+		}
+	}
+
+	// Disabling all but one backend is fine
+	settings := DefaultSettings()
+	settings.EnabledBackends["winget"] = false
+	settings.EnabledBackends["choco"] = false
+	if err := settings.Validate(testSources); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestSettingsClone(t *testing.T) {
+	settings := DefaultSettings()
+	settings.EnabledBackends["choco"] = false
+	clone := settings.Clone()
+	clone.EnabledBackends["choco"] = true
+	clone.InstallConcurrency["winget"] = 2
+	if settings.IsBackendEnabled("choco") || settings.Concurrency("winget") != defaultInstallConcurrency {
+		t.Error("changing the clone changed the original")
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"database/sql"
+	"sort"
+	"strings"
+)
+
+// Tags are the user's own labels on saved apps, like "dev" or
+// "needs-license", unlike PackageDetails.Tags which come from the package
+// source. Each saved app can have any number of them.
+
+// normalizeTags trims and lower-cases tags, dropping empty and duplicate ones
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool)
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// parseTags splits comma separated tags as typed by the user or stored in a CSV
+func parseTags(text string) []string {
+	return normalizeTags(strings.Split(text, ","))
+}
+
+// formatTags joins tags the way parseTags reads them
+func formatTags(tags []string) string {
+	return strings.Join(tags, ", ")
+}
+
+// hasTag reports whether tags contains tag
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// matchingTag returns the first tag containing query, ignoring case, or ""
+func matchingTag(tags []string, query string) string {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return ""
+	}
+	for _, tag := range tags {
+		if strings.Contains(tag, query) {
+			return tag
+		}
+	}
+	return ""
+}
+
+// SetSavedAppTags replaces the tags of a saved app. Tags no app uses any more
+// are removed.
+func SetSavedAppTags(db *sql.DB, listID int64, source, packageID string, tags []string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var appID int64
+	err = tx.QueryRow(`SELECT id FROM saved_apps WHERE list_id = ? AND source = ? AND package_id = ?`,
+		listID, source, packageID).Scan(&appID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM saved_app_tags WHERE saved_app_id = ?`, appID); err != nil {
+		return err
+	}
+	for _, tag := range normalizeTags(tags) {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO tags (name) VALUES (?)`, tag); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`INSERT INTO saved_app_tags (saved_app_id, tag_id) SELECT ?, id FROM tags WHERE name = ?`, appID, tag)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM saved_app_tags)`); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetAllTags returns every tag in use by a saved app, sorted
+func GetAllTags(db *sql.DB) ([]string, error) {
+	query := `
+	SELECT DISTINCT t.name
+	FROM tags t
+	INNER JOIN saved_app_tags st ON st.tag_id = t.id
+	INNER JOIN saved_apps sa ON sa.id = st.saved_app_id
+	ORDER BY t.name
+	`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// GetTagsInList returns every tag in use by an app in a list, sorted
+func GetTagsInList(db *sql.DB, listID int64) ([]string, error) {
+	appTags, err := getListTags(db, listID)
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	for _, t := range appTags {
+		tags = append(tags, t...)
+	}
+	return normalizeTags(tags), nil
+}
+
+// getListTags returns the tags of the apps in a list by saved app ID
+func getListTags(db *sql.DB, listID int64) (map[int64][]string, error) {
+	query := `
+	SELECT st.saved_app_id, t.name
+	FROM saved_app_tags st
+	INNER JOIN tags t ON t.id = st.tag_id
+	INNER JOIN saved_apps sa ON sa.id = st.saved_app_id
+	WHERE sa.list_id = ?
+	ORDER BY t.name
+	`
+
+	rows, err := db.Query(query, listID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make(map[int64][]string)
+	for rows.Next() {
+		var appID int64
+		var tag string
+		if err := rows.Scan(&appID, &tag); err != nil {
+			return nil, err
+		}
+		tags[appID] = append(tags[appID], tag)
+	}
+	return tags, rows.Err()
+}
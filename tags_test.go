@@ -0,0 +1,131 @@
+package main
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	got := parseTags(" Dev, security-tool,,dev , Needs-License ")
+	want := []string{"dev", "needs-license", "security-tool"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if again := parseTags(formatTags(got)); !reflect.DeepEqual(again, want) {
+		t.Errorf("formatted tags read back as %q", again)
+	}
+	if got := parseTags(""); len(got) != 0 {
+		t.Errorf("got %q for no tags", got)
+	}
+}
+
+func TestMatchingTag(t *testing.T) {
+	tags := []string{"dev", "security-tool"}
+	if got := matchingTag(tags, "Security"); got != "security-tool" {
+		t.Errorf("got %q, want security-tool", got)
+	}
+	if got := matchingTag(tags, "license"); got != "" {
+		t.Errorf("got %q for a tag that is not there", got)
+	}
+	if got := matchingTag(tags, " "); got != "" {
+		t.Errorf("an empty query matched %q", got)
+	}
+}
+
+// saveTestApp saves a bare app to a list, returning its ID
+func saveTestApp(t *testing.T, db *sql.DB, listID int64, source, packageID string) int64 {
+	t.Helper()
+	result, err := db.Exec(`INSERT INTO saved_apps (list_id, name, package_id, source) VALUES (?, ?, ?, ?)`,
+		listID, packageID, packageID, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestSavedAppTags(t *testing.T) {
+	db, path := openTestDB(t)
+	if err := migrateDatabase(db, path); err != nil {
+		t.Fatal(err)
+	}
+	workList, err := db.Exec(`INSERT INTO lists (name) VALUES ('Work')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	workID, _ := workList.LastInsertId()
+
+	gitID := saveTestApp(t, db, 1, "winget", "Git.Git")
+	chocoGitID := saveTestApp(t, db, 1, "choco", "git")
+	saveTestApp(t, db, workID, "winget", "Git.Git")
+
+	if err := SetSavedAppTags(db, 1, "winget", "Git.Git", []string{"Dev", "vcs"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSavedAppTags(db, 1, "choco", "git", []string{"dev", "needs-license"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSavedAppTags(db, workID, "winget", "Git.Git", []string{"work"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSavedAppTags(db, 1, "scoop", "git", []string{"dev"}); err == nil {
+		t.Error("tagged an app that is not saved")
+	}
+
+	// Tags belong to the app in one list
+	tags, err := getListTags(db, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int64][]string{gitID: {"dev", "vcs"}, chocoGitID: {"dev", "needs-license"}}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("got tags %v, want %v", tags, want)
+	}
+
+	// The tag filter of a list offers only that list's tags
+	listTags, err := GetTagsInList(db, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"dev", "needs-license", "vcs"}; !reflect.DeepEqual(listTags, want) {
+		t.Errorf("got list tags %v, want %v", listTags, want)
+	}
+	if workTags, _ := GetTagsInList(db, workID); !reflect.DeepEqual(workTags, []string{"work"}) {
+		t.Errorf("got work list tags %v, want [work]", workTags)
+	}
+
+	all, err := GetAllTags(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"dev", "needs-license", "vcs", "work"}; !reflect.DeepEqual(all, want) {
+		t.Errorf("got all tags %v, want %v", all, want)
+	}
+
+	// Replacing tags drops the ones no app uses any more
+	if err := SetSavedAppTags(db, 1, "choco", "git", []string{"dev"}); err != nil {
+		t.Fatal(err)
+	}
+	var unused int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM tags WHERE name = 'needs-license'`).Scan(&unused); err != nil {
+		t.Fatal(err)
+	}
+	if unused != 0 {
+		t.Error("an unused tag was kept")
+	}
+
+	// Removing an app removes its tags
+	if _, err := db.Exec(`DELETE FROM saved_apps WHERE id = ?`, gitID); err != nil {
+		t.Fatal(err)
+	}
+	if tags, _ := getListTags(db, 1); !reflect.DeepEqual(tags, map[int64][]string{chocoGitID: {"dev"}}) {
+		t.Errorf("got tags %v after removing Git.Git", tags)
+	}
+	if all, _ := GetAllTags(db); !reflect.DeepEqual(all, []string{"dev", "work"}) {
+		t.Errorf("got all tags %v after removing Git.Git", all)
+	}
+}
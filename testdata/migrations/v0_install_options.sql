@@ -0,0 +1,46 @@
+-- Install options release, created fresh by that release
+CREATE TABLE lists (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE,
+	description TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE saved_apps (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	list_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	package_id TEXT NOT NULL,
+	version TEXT,
+	source TEXT NOT NULL,
+	description TEXT,
+	publisher TEXT DEFAULT '',
+	homepage TEXT DEFAULT '',
+	license TEXT DEFAULT '',
+	tags TEXT DEFAULT '',
+	release_notes TEXT DEFAULT '',
+	required_version TEXT DEFAULT '',
+	pinned INTEGER DEFAULT 0,
+	install_scope TEXT DEFAULT '',
+	install_architecture TEXT DEFAULT '',
+	install_location TEXT DEFAULT '',
+	install_override TEXT DEFAULT '',
+	install_custom_args TEXT DEFAULT '',
+	install_interactive INTEGER DEFAULT 0,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (list_id) REFERENCES lists(id) ON DELETE CASCADE,
+	UNIQUE(list_id, package_id)
+);
+
+CREATE INDEX idx_package_id ON saved_apps(package_id);
+CREATE INDEX idx_source ON saved_apps(source);
+CREATE INDEX idx_list_id ON saved_apps(list_id);
+CREATE INDEX idx_list_name ON lists(name);
+
+INSERT INTO lists (id, name, description) VALUES (1, 'Default', 'Default saved applications list');
+INSERT INTO lists (id, name, description) VALUES (2, 'Work', 'Work machine');
+
+INSERT INTO saved_apps (list_id, name, package_id, version, source, description, required_version, pinned)
+	VALUES (1, 'Git', 'Git.Git', '2.43.0', 'winget', 'Distributed version control', '2.43.0', 1);
+INSERT INTO saved_apps (list_id, name, package_id, version, source, description, install_scope, install_custom_args) VALUES (1, '7-Zip', '7zip', '23.1.0', 'choco', 'File archiver', 'machine', '/S');
+INSERT INTO saved_apps (list_id, name, package_id, version, source, description) VALUES (2, 'Git', 'Git.Git', '2.43.0', 'winget', 'Distributed version control');
@@ -0,0 +1,40 @@
+-- Package details release, upgraded from the first release: the detail
+-- columns were appended by ALTER TABLE
+CREATE TABLE lists (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE,
+	description TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE saved_apps (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	list_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	package_id TEXT NOT NULL,
+	version TEXT,
+	source TEXT NOT NULL,
+	description TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (list_id) REFERENCES lists(id) ON DELETE CASCADE,
+	UNIQUE(list_id, package_id)
+);
+
+ALTER TABLE saved_apps ADD COLUMN publisher TEXT DEFAULT '';
+ALTER TABLE saved_apps ADD COLUMN homepage TEXT DEFAULT '';
+ALTER TABLE saved_apps ADD COLUMN license TEXT DEFAULT '';
+ALTER TABLE saved_apps ADD COLUMN tags TEXT DEFAULT '';
+ALTER TABLE saved_apps ADD COLUMN release_notes TEXT DEFAULT '';
+
+CREATE INDEX idx_package_id ON saved_apps(package_id);
+CREATE INDEX idx_source ON saved_apps(source);
+CREATE INDEX idx_list_id ON saved_apps(list_id);
+CREATE INDEX idx_list_name ON lists(name);
+
+INSERT INTO lists (id, name, description) VALUES (1, 'Default', 'Default saved applications list');
+INSERT INTO lists (id, name, description) VALUES (2, 'Work', 'Work machine');
+
+INSERT INTO saved_apps (list_id, name, package_id, version, source, description, publisher, homepage, license, tags)
+	VALUES (1, 'Git', 'Git.Git', '2.43.0', 'winget', 'Distributed version control', 'The Git Development Community', 'https://git-scm.com', 'GPL-2.0', '["git","vcs"]');
+INSERT INTO saved_apps (list_id, name, package_id, version, source, description) VALUES (1, '7-Zip', '7zip', '23.1.0', 'choco', 'File archiver');
+INSERT INTO saved_apps (list_id, name, package_id, version, source, description) VALUES (2, 'Git', 'Git.Git', '2.43.0', 'winget', 'Distributed version control');
@@ -1,26 +1,101 @@
 package main
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type AppInfo struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	PackageID   string `json:"package_id"`
-	Version     string `json:"version"`
-	Source      string `json:"source"` // "winget" or "chocolatey"
-	Description string `json:"description"`
-	IsInstalled bool   `json:"is_installed"`
-	IsSaved     bool   `json:"is_saved"`
-	ListID      int64  `json:"list_id"` // Which list this app is saved to
+	ID               int      `json:"id"`
+	Name             string   `json:"name"`
+	PackageID        string   `json:"package_id"`
+	Version          string   `json:"version"`
+	InstalledVersion string   `json:"installed_version"` // Version currently on the machine
+	AvailableVersion string   `json:"available_version"` // Newer version offered by the source
+	Source           string   `json:"source"`            // Backend source name, e.g. "winget"
+	Repository       string   `json:"repository"`        // Source within the backend, e.g. winget's "msstore"
+	Match            string   `json:"match"`             // Why a search matched when not by name, e.g. "Tag: editor"
+	Description      string   `json:"description"`
+	IsInstalled      bool     `json:"is_installed"`
+	IsSaved          bool     `json:"is_saved"`
+	ListID           int64    `json:"list_id"`          // Which list this app is saved to
+	RequiredVersion  string   `json:"required_version"` // Version a list installs, "" or "latest" for the newest
+	Pinned           bool     `json:"pinned"`           // Upgrades skip this app
+	Tags             []string `json:"tags"`             // The user's tags on the saved app, sorted
+
+	Options InstallOptions `json:"options"` // Installer options used when installing from a list
+
+	Details *PackageDetails `json:"details,omitempty"` // Nil until fetched from the backend or loaded from saved_apps
+}
+
+// requiredVersion returns the exact version app must be installed at, or ""
+// when the latest version will do
+func requiredVersion(app *AppInfo) string {
+	if strings.EqualFold(app.RequiredVersion, "latest") {
+		return ""
+	}
+	return app.RequiredVersion
+}
+
+// versionMismatch reports whether app is installed at a different version
+// than its list requires
+func versionMismatch(app *AppInfo) bool {
+	required := requiredVersion(app)
+	return app.IsInstalled && required != "" && app.InstalledVersion != "" && app.InstalledVersion != required
+}
+
+// PackageDetails is the metadata shown in the detail pane, as reported by
+// "winget show", "choco info" or "scoop info"
+type PackageDetails struct {
+	Publisher    string   `json:"publisher"`
+	Homepage     string   `json:"homepage"`
+	License      string   `json:"license"`
+	Description  string   `json:"description"`
+	Tags         []string `json:"tags"`
+	ReleaseNotes string   `json:"release_notes"`
 }
 
 type AppList struct {
 	ID          int64     `json:"id"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
+	IsDefault   bool      `json:"is_default"` // Used by the legacy helpers and selected on startup
 	CreatedAt   time.Time `json:"created_at"`
 }
 
+// InstallOutcome is how an installation recorded in the history ended
+type InstallOutcome string
+
+const (
+	OutcomeSucceeded InstallOutcome = "Succeeded"
+	OutcomeFailed    InstallOutcome = "Failed"
+	OutcomeCancelled InstallOutcome = "Cancelled"
+	OutcomeRestart   InstallOutcome = "Restart required"
+)
+
+// InstallRecord is one row of the install history
+type InstallRecord struct {
+	ID         int64          `json:"id"`
+	Name       string         `json:"name"`
+	PackageID  string         `json:"package_id"`
+	Source     string         `json:"source"`
+	Version    string         `json:"version"`   // Version asked for, "latest" when none was required
+	ListID     int64          `json:"list_id"`   // List the app was installed from, 0 for search results
+	ListName   string         `json:"list_name"` // Kept so the history survives deleting the list
+	StartedAt  time.Time      `json:"started_at"`
+	FinishedAt time.Time      `json:"finished_at"`
+	Outcome    InstallOutcome `json:"outcome"`
+	Error      string         `json:"error"`
+	Log        string         `json:"log"` // Captured installer output
+}
+
+// HistoryFilter narrows the install history. Zero fields match everything.
+type HistoryFilter struct {
+	Since   time.Time
+	Outcome InstallOutcome
+	ListID  int64
+}
+
 type ImportResult struct {
 	Filepath      string `json:"filepath"`
 	ListName      string `json:"list_name"`
@@ -4,14 +4,22 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"image/color"
 	"log"
+	"net/url"
 	"runtime/debug"
 	"strings"
+	"sync"
+	"time"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
@@ -32,6 +40,12 @@ func createSearchPanel(appManager *AppManager) *fyne.Container {
 	log.Println("Search entry created successfully")
 
 	log.Println("Creating search button...")
+	// Cancel is only enabled while a search is running
+	cancelSearchButton := widget.NewButtonWithIcon("Cancel", theme.CancelIcon(), func() {
+		appManager.CancelSearch()
+	})
+	cancelSearchButton.Disable()
+
 	searchButton := widget.NewButtonWithIcon("Search", theme.SearchIcon(), nil)
 	searchButton.Importance = widget.HighImportance // Prominent blue styling
 	searchButton.OnTapped = func() {
@@ -50,6 +64,7 @@ func createSearchPanel(appManager *AppManager) *fyne.Container {
 
 		searchButton.SetText("Searching...")
 		searchButton.Disable()
+		cancelSearchButton.Enable()
 
 		go func() {
 			defer func() {
@@ -58,8 +73,9 @@ func createSearchPanel(appManager *AppManager) *fyne.Container {
 				}
 				searchButton.SetText("Search")
 				searchButton.Enable()
+				cancelSearchButton.Disable()
 			}()
-			appManager.SearchApps(query)
+			appManager.SearchApps(context.Background(), query)
 		}()
 	}
 	log.Println("Search button created successfully")
@@ -84,37 +100,77 @@ func createSearchPanel(appManager *AppManager) *fyne.Container {
 	// Filter radio buttons
 	var filterGroup *widget.RadioGroup
 	filterGroup = widget.NewRadioGroup(
-		[]string{"All Results", "Installed Only", "Saved Apps"},
+		[]string{"All Results", "Installed Only", "Saved Apps", "Updates Available"},
 		func(value string) {
 			// Use unified filtering system
 			appManager.SetViewFilter(value)
+
+			// Pending upgrades are only fetched when the view is opened
+			if value == "Updates Available" {
+				go func() {
+					defer func() {
+						if r := recover(); r != nil {
+							// Handle panic gracefully
+						}
+					}()
+					appManager.RefreshUpgradableApps(context.Background())
+				}()
+			}
 		},
 	)
 	filterGroup.SetSelected("Installed Only") // Default to installed since that's what we load on startup
 	log.Println("Filter radio group created successfully")
 
 	log.Println("Creating source radio group...")
-	// Source filter
+	// Source filter - one option per registered backend
+	sourceOptions := []string{"All Sources"}
+	sourceNames := map[string]string{"All Sources": "All Sources"}
+	for _, backend := range appManager.Backends().All() {
+		sourceOptions = append(sourceOptions, backend.DisplayName())
+		sourceNames[backend.DisplayName()] = backend.Name()
+	}
 	sourceGroup := widget.NewRadioGroup(
-		[]string{"All Sources", "Winget", "Chocolatey"},
+		sourceOptions,
 		func(value string) {
 			// Filter current apps by source
-			switch value {
-			case "All Sources":
-				// Show all current apps
-				appManager.FilterBySource("All Sources")
-			case "Winget":
-				// Show only winget apps
-				appManager.FilterBySource("winget")
-			case "Chocolatey":
-				// Show only chocolatey apps
-				appManager.FilterBySource("chocolatey")
+			if source, ok := sourceNames[value]; ok {
+				appManager.FilterBySource(source)
 			}
 		},
 	)
 	sourceGroup.SetSelected("All Sources")
 	log.Println("Source radio group created successfully")
 
+	log.Println("Creating tag selector...")
+	// Tag filter - options follow the tags in use in the current list
+	tagSelect := widget.NewSelect([]string{"All Tags"}, func(selected string) {
+		if selected != appManager.GetCurrentTagFilter() {
+			appManager.FilterByTag(selected)
+		}
+	})
+	tagSelect.SetSelected("All Tags")
+	updateTagSelector := func() {
+		options := append([]string{"All Tags"}, appManager.GetCurrentListTags()...)
+		if strings.Join(options, "\n") != strings.Join(tagSelect.Options, "\n") {
+			tagSelect.Options = options
+			tagSelect.Refresh()
+		}
+
+		// The filter goes back to every app when switching to a list without its tag
+		if selected := appManager.GetCurrentTagFilter(); tagSelect.Selected != selected {
+			tagSelect.SetSelected(selected)
+		}
+	}
+	appManager.AddCallback(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				// Handle panic gracefully
+			}
+		}()
+		updateTagSelector()
+	})
+	log.Println("Tag selector created successfully")
+
 	log.Println("Creating list selector...")
 	// List selector
 	listSelect := widget.NewSelect([]string{}, func(selected string) {
@@ -251,15 +307,67 @@ func createSearchPanel(appManager *AppManager) *fyne.Container {
 				refreshButton.SetText("Refresh Installed")
 				refreshButton.Enable()
 			}()
-			appManager.RefreshInstalledApps()
+			appManager.RefreshInstalledApps(context.Background())
 		}()
 	}
 	log.Println("Refresh button created successfully")
 
 	log.Println("Creating install all button...")
+	var stopOnFailureCheck *widget.Check
 	installAllButton := widget.NewButtonWithIcon("Install All in List", theme.DownloadIcon(), nil)
 	installAllButton.Importance = widget.HighImportance // Prominent styling
-	installAllButton.OnTapped = func() {
+
+	// runBatchInstall runs a batch install in the background. While it runs
+	// the Install All button cancels the batch.
+	var installAll func()
+	var runBatchInstall func(install func() (*BatchReport, error))
+	runBatchInstall = func(install func() (*BatchReport, error)) {
+		// Get the main window for dialogs
+		windows := fyne.CurrentApp().Driver().AllWindows()
+		if len(windows) == 0 {
+			return
+		}
+		mainWindow := windows[0]
+
+		if appManager.IsBatchRunning() {
+			dialog.ShowInformation("Batch Running", "Wait for the running batch install to finish or cancel it first.", mainWindow)
+			return
+		}
+
+		installAllButton.SetText("Cancel Batch")
+		installAllButton.SetIcon(theme.CancelIcon())
+		installAllButton.OnTapped = func() {
+			installAllButton.SetText("Cancelling...")
+			installAllButton.Disable()
+			appManager.CancelBatch()
+		}
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					// Handle panic gracefully
+				}
+				installAllButton.SetText("Install All in List")
+				installAllButton.SetIcon(theme.DownloadIcon())
+				installAllButton.OnTapped = installAll
+				installAllButton.Enable()
+			}()
+
+			report, err := install()
+			if err != nil {
+				showOperationError(err, mainWindow)
+				return
+			}
+			showBatchReport(mainWindow, appManager, report, func(failed []*AppInfo) {
+				runBatchInstall(func() (*BatchReport, error) {
+					retried := appManager.InstallApps(context.Background(), failed, BatchOptions{})
+					retried.ListName = report.ListName
+					return retried, nil
+				})
+			})
+		}()
+	}
+
+	installAll = func() {
 		currentList := appManager.GetCurrentList()
 		if currentList == nil {
 			// Get the main window for dialogs
@@ -271,18 +379,33 @@ func createSearchPanel(appManager *AppManager) *fyne.Container {
 			return
 		}
 
-		installAllButton.SetText("Installing...")
-		installAllButton.Disable()
+		options := BatchOptions{StopOnFailure: stopOnFailureCheck.Checked}
+		runBatchInstall(func() (*BatchReport, error) {
+			return appManager.InstallAllAppsInCurrentList(context.Background(), options)
+		})
+	}
+	installAllButton.OnTapped = installAll
+	stopOnFailureCheck = widget.NewCheck("Stop on first failure", nil)
+	log.Println("Install all button created successfully")
+
+	log.Println("Creating upgrade all button...")
+	upgradeAllButton := widget.NewButtonWithIcon("Upgrade All", theme.MoveUpIcon(), nil)
+	upgradeAllButton.Importance = widget.MediumImportance
+	upgradeAllButton.OnTapped = func() {
+		upgradeAllButton.SetText("Upgrading...")
+		upgradeAllButton.Disable()
 		go func() {
 			defer func() {
 				if r := recover(); r != nil {
 					// Handle panic gracefully
 				}
-				installAllButton.SetText("Install All in List")
-				installAllButton.Enable()
+				upgradeAllButton.SetText("Upgrade All")
+				upgradeAllButton.Enable()
 			}()
 
-			err := appManager.InstallAllAppsInCurrentList()
+			// Make sure we upgrade against a fresh list of pending updates
+			appManager.RefreshUpgradableApps(context.Background())
+			summary, err := appManager.UpgradeAllApps(context.Background())
 
 			// Get the main window for dialogs
 			windows := fyne.CurrentApp().Driver().AllWindows()
@@ -293,22 +416,28 @@ func createSearchPanel(appManager *AppManager) *fyne.Container {
 
 			if err != nil {
 				dialog.ShowError(err, mainWindow)
+			} else if summary.Summary() == "" {
+				dialog.ShowInformation("Up to Date", "All installed applications are up to date.", mainWindow)
+			} else if !summary.Changed() && summary.Pinned > 0 {
+				dialog.ShowInformation("Nothing Upgraded",
+					fmt.Sprintf("%s. Unpin applications in Install Options to upgrade them.", summary.Summary()), mainWindow)
+			} else if !summary.Changed() {
+				dialog.ShowInformation("Nothing Upgraded", summary.Summary()+".", mainWindow)
 			} else {
-				listName := "list"
-				if currentList := appManager.GetCurrentList(); currentList != nil {
-					listName = fmt.Sprintf("'%s'", currentList.Name)
-				}
-				dialog.ShowInformation("Success", fmt.Sprintf("All applications in %s have been installed.", listName), mainWindow)
+				dialog.ShowInformation("Success", summary.Summary()+".", mainWindow)
 			}
+
+			// Upgrades that finished before a failure may still need a restart
+			showRestartPrompt(mainWindow, appManager, summary.RestartRequired)
 		}()
 	}
-	log.Println("Install all button created successfully")
+	log.Println("Upgrade all button created successfully")
 
 	log.Println("Creating container layout...")
 	container := container.NewVBox(
 		widget.NewCard("Search", "", container.NewVBox(
 			searchEntry,
-			container.NewHBox(searchButton, clearButton),
+			container.NewHBox(searchButton, cancelSearchButton, clearButton),
 		)),
 		widget.NewCard("Filter", "", container.NewVBox(
 			filterGroup,
@@ -316,6 +445,9 @@ func createSearchPanel(appManager *AppManager) *fyne.Container {
 		widget.NewCard("Source", "", container.NewVBox(
 			sourceGroup,
 		)),
+		widget.NewCard("Tags", "", container.NewVBox(
+			tagSelect,
+		)),
 		widget.NewCard("Lists", "", container.NewVBox(
 			listSelect,
 			manageListsButton,
@@ -325,6 +457,8 @@ func createSearchPanel(appManager *AppManager) *fyne.Container {
 		widget.NewCard("Actions", "", container.NewVBox(
 			refreshButton,
 			installAllButton,
+			stopOnFailureCheck,
+			upgradeAllButton,
 		)),
 	)
 	log.Println("Container layout created successfully")
@@ -335,7 +469,8 @@ func createSearchPanel(appManager *AppManager) *fyne.Container {
 func createAppListPanel(appManager *AppManager) *fyne.Container {
 	log.Println("Creating app list widget...")
 	// Create the list
-	appList := widget.NewList(
+	var appList *widget.List
+	appList = widget.NewList(
 		func() int {
 			defer func() {
 				if r := recover(); r != nil {
@@ -357,9 +492,21 @@ func createAppListPanel(appManager *AppManager) *fyne.Container {
 			apps := appManager.GetCurrentApps()
 			if id >= 0 && id < len(apps) {
 				updateAppListItem(obj, apps[id], appManager)
+				// Rows grow while an install log is shown
+				appList.SetItemHeight(id, obj.MinSize().Height)
 			}
 		},
 	)
+
+	// Redraw rows as installation output arrives
+	appManager.AddProgressCallback(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				// Handle panic gracefully
+			}
+		}()
+		appList.Refresh()
+	})
 	log.Println("App list widget created successfully")
 
 	log.Println("Creating empty state labels...")
@@ -393,6 +540,13 @@ func createAppListPanel(appManager *AppManager) *fyne.Container {
 	contentStack := container.NewStack(appList, emptyContainer)
 	log.Println("Content stack created successfully")
 
+	// Banner with the outcome of the search on each package manager
+	searchStatusIcon := widget.NewIcon(theme.InfoIcon())
+	searchStatusLabel := widget.NewLabel("")
+	searchStatusLabel.Wrapping = fyne.TextWrapWord
+	searchStatusBanner := container.NewBorder(nil, nil, searchStatusIcon, nil, searchStatusLabel)
+	searchStatusBanner.Hide()
+
 	log.Println("Adding callback to app manager...")
 	// Add callback to refresh list and update empty state when data changes
 	appManager.AddCallback(func() {
@@ -402,8 +556,13 @@ func createAppListPanel(appManager *AppManager) *fyne.Container {
 			}
 		}()
 
-		// Check if we're currently loading
-		if appManager.IsLoading() {
+		updateSearchStatusBanner(searchStatusBanner, searchStatusIcon, searchStatusLabel, appManager)
+
+		apps := appManager.GetCurrentApps()
+
+		// Check if we're currently loading. Searches show their results as
+		// each package manager answers.
+		if appManager.IsLoading() && len(apps) == 0 {
 			// Show loading state with context-aware message
 			emptyIconLabel.SetText("⏳")
 			currentViewFilter := appManager.GetCurrentViewFilter()
@@ -413,6 +572,8 @@ func createAppListPanel(appManager *AppManager) *fyne.Container {
 				loadingMessage = "Searching for applications..."
 			} else if currentViewFilter == "Installed Only" {
 				loadingMessage = "Refreshing installed applications..."
+			} else if currentViewFilter == "Updates Available" {
+				loadingMessage = "Checking for updates..."
 			} else {
 				loadingMessage = "Loading applications..."
 			}
@@ -426,7 +587,6 @@ func createAppListPanel(appManager *AppManager) *fyne.Container {
 		// Reset icon when not loading
 		emptyIconLabel.SetText("📦")
 
-		apps := appManager.GetCurrentApps()
 		if len(apps) == 0 {
 			// Show empty state with appropriate message
 			updateEmptyStateMessage(emptyMessageLabel, appManager)
@@ -448,20 +608,403 @@ func createAppListPanel(appManager *AppManager) *fyne.Container {
 	headerLabel.TextStyle = fyne.TextStyle{Bold: true}
 	log.Println("Status and header labels created successfully")
 
+	log.Println("Creating detail pane...")
+	// Detail pane for the selected application
+	detailPane, showDetails := createDetailPane(appManager, func() {
+		appList.UnselectAll()
+	})
+	appList.OnSelected = func(id widget.ListItemID) {
+		apps := appManager.GetCurrentApps()
+		if id >= 0 && id < len(apps) {
+			showDetails(apps[id])
+		}
+	}
+	log.Println("Detail pane created successfully")
+
 	log.Println("Creating border container...")
 	// Use border container to give maximum space to the content
 	borderContainer := container.NewBorder(
-		container.NewVBox(headerLabel, widget.NewSeparator()), // top: header
-		nil,          // bottom: no status label
-		nil,          // left
-		nil,          // right
-		contentStack, // center: stack of list or empty state
+		container.NewVBox(headerLabel, searchStatusBanner, widget.NewSeparator()), // top: header and search status
+		createInstallQueuePanel(appManager),                                       // bottom: install queue
+		nil,                                                                       // left
+		detailPane,                                                                // right: details of the selected app
+		contentStack,                                                              // center: stack of list or empty state
 	)
 	log.Println("Border container created successfully")
 
 	return borderContainer
 }
 
+// createInstallQueuePanel shows the waiting, running and finished installs
+// with buttons to reorder, pause, cancel and retry them. It stays hidden
+// while the queue is empty.
+func createInstallQueuePanel(appManager *AppManager) *fyne.Container {
+	var jobs []InstallJob
+
+	queueLabel := widget.NewLabel("Install Queue")
+	queueLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	pauseQueueButton := widget.NewButtonWithIcon("Pause Queue", theme.MediaPauseIcon(), nil)
+	pauseQueueButton.OnTapped = func() {
+		appManager.SetInstallQueuePaused(!appManager.IsInstallQueuePaused())
+	}
+	clearButton := widget.NewButtonWithIcon("Clear Finished", theme.ContentClearIcon(), func() {
+		appManager.ClearFinishedInstallJobs()
+	})
+
+	jobList := widget.NewList(
+		func() int {
+			return len(jobs)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Truncation = fyne.TextTruncateEllipsis
+			buttons := container.NewHBox(
+				widget.NewButtonWithIcon("", theme.MoveUpIcon(), nil),
+				widget.NewButtonWithIcon("", theme.MoveDownIcon(), nil),
+				widget.NewButtonWithIcon("", theme.MediaPauseIcon(), nil),
+				widget.NewButtonWithIcon("", theme.CancelIcon(), nil),
+				widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), nil),
+			)
+			return container.NewBorder(nil, nil, widget.NewIcon(theme.DownloadIcon()), buttons, label)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			defer func() {
+				if r := recover(); r != nil {
+					// Handle panic gracefully
+				}
+			}()
+			if id < 0 || id >= len(jobs) {
+				return
+			}
+			job := jobs[id]
+			row := obj.(*fyne.Container)
+
+			// Border layout keeps the center object first
+			if label, ok := row.Objects[0].(*widget.Label); ok {
+				text := fmt.Sprintf("%s (%s)   %s   %s", job.App.Name, job.App.PackageID, job.App.Source, job.State)
+				if job.State == JobFailed && job.Err != nil {
+					text += "   " + job.Err.Error()
+				}
+				label.SetText(text)
+			}
+			if icon, ok := row.Objects[1].(*widget.Icon); ok {
+				switch job.State {
+				case JobRunning:
+					icon.SetResource(theme.DownloadIcon())
+				case JobSucceeded:
+					icon.SetResource(theme.ConfirmIcon())
+				case JobFailed:
+					icon.SetResource(theme.ErrorIcon())
+				case JobCancelled:
+					icon.SetResource(theme.CancelIcon())
+				case JobPaused:
+					icon.SetResource(theme.MediaPauseIcon())
+				default:
+					icon.SetResource(theme.HistoryIcon())
+				}
+			}
+
+			buttons, ok := row.Objects[2].(*fyne.Container)
+			if !ok || len(buttons.Objects) < 5 {
+				return
+			}
+			waiting := job.State == JobQueued || job.State == JobPaused
+			setButton := func(index int, enabled bool, action func()) {
+				if button, ok := buttons.Objects[index].(*widget.Button); ok {
+					button.OnTapped = action
+					if enabled {
+						button.Enable()
+					} else {
+						button.Disable()
+					}
+				}
+			}
+			setButton(0, waiting && id > 0, func() { appManager.MoveInstallJob(job.ID, -1) })
+			setButton(1, waiting && id < len(jobs)-1, func() { appManager.MoveInstallJob(job.ID, 1) })
+			if pauseButton, ok := buttons.Objects[2].(*widget.Button); ok {
+				if job.State == JobPaused {
+					pauseButton.SetIcon(theme.MediaPlayIcon())
+				} else {
+					pauseButton.SetIcon(theme.MediaPauseIcon())
+				}
+			}
+			setButton(2, waiting, func() {
+				if job.State == JobPaused {
+					appManager.ResumeInstallJob(job.ID)
+				} else {
+					appManager.PauseInstallJob(job.ID)
+				}
+			})
+			setButton(3, !job.State.finished(), func() { appManager.CancelInstallJob(job.ID) })
+			setButton(4, job.State == JobFailed || job.State == JobCancelled, func() { appManager.RetryInstallJob(job.ID) })
+		},
+	)
+
+	// Keep a few rows visible without taking over the app list
+	listSpacer := canvas.NewRectangle(color.Transparent)
+	listSpacer.SetMinSize(fyne.NewSize(0, 150))
+
+	panel := container.NewBorder(
+		container.NewVBox(widget.NewSeparator(), container.NewHBox(queueLabel, layout.NewSpacer(), pauseQueueButton, clearButton)), // top
+		nil,                                     // bottom
+		nil,                                     // left
+		nil,                                     // right
+		container.NewStack(listSpacer, jobList), // center
+	)
+
+	update := func() {
+		defer func() {
+			if r := recover(); r != nil {
+				// Handle panic gracefully
+			}
+		}()
+		jobs = appManager.InstallJobs()
+		if len(jobs) == 0 {
+			panel.Hide()
+			return
+		}
+
+		running, waiting := 0, 0
+		for _, job := range jobs {
+			switch job.State {
+			case JobRunning:
+				running++
+			case JobQueued, JobPaused:
+				waiting++
+			}
+		}
+		queueLabel.SetText(fmt.Sprintf("Install Queue (%d running, %d waiting)", running, waiting))
+		if appManager.IsInstallQueuePaused() {
+			pauseQueueButton.SetText("Resume Queue")
+			pauseQueueButton.SetIcon(theme.MediaPlayIcon())
+		} else {
+			pauseQueueButton.SetText("Pause Queue")
+			pauseQueueButton.SetIcon(theme.MediaPauseIcon())
+		}
+		panel.Show()
+		jobList.Refresh()
+	}
+	appManager.AddProgressCallback(update)
+	update()
+
+	return panel
+}
+
+// showOperationError reports a failed install, upgrade, uninstall or pin. Known
+// failures come with a hint, and outcomes that are not really failures are
+// shown as information. Cancellations are not reported.
+func showOperationError(err error, parent fyne.Window) {
+	hint := ErrorHint(err)
+	switch {
+	case errors.Is(err, context.Canceled):
+		return
+	case errors.Is(err, ErrRebootRequired):
+		dialog.ShowInformation("Restart Required", fmt.Sprintf("%v\n\n%s", err, hint), parent)
+	case errors.Is(err, ErrAlreadyInstalled), errors.Is(err, ErrNoApplicableUpgrade):
+		dialog.ShowInformation("Nothing to Do", fmt.Sprintf("%v\n\n%s", err, hint), parent)
+	case hint != "":
+		dialog.ShowError(fmt.Errorf("%v\n\n%s", err, hint), parent)
+	default:
+		dialog.ShowError(err, parent)
+	}
+}
+
+// showRestartPrompt lists the apps of the operation that just finished which
+// wait for a restart, and offers to restart now, or later so more apps can be
+// installed first
+func showRestartPrompt(parent fyne.Window, appManager *AppManager, apps []*AppInfo) {
+	if len(apps) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(apps))
+	for _, app := range apps {
+		names = append(names, "• "+app.Name)
+	}
+	message := fmt.Sprintf("These applications need a restart to finish installing:\n\n%s\n\nRestart now, or continue installing and restart later?",
+		strings.Join(names, "\n"))
+
+	confirm := dialog.NewConfirm("Restart Required", message, func(restart bool) {
+		if !restart {
+			return
+		}
+		if err := appManager.RestartComputer(); err != nil {
+			dialog.ShowError(err, parent)
+		}
+	}, parent)
+	confirm.SetConfirmText("Restart Now")
+	confirm.SetDismissText("Later")
+	confirm.Show()
+}
+
+// updateSearchStatusBanner shows how each package manager answered the
+// current search, with a warning icon when one of them failed
+func updateSearchStatusBanner(banner *fyne.Container, icon *widget.Icon, label *widget.Label, appManager *AppManager) {
+	statuses := appManager.GetSearchStatus()
+	if len(statuses) == 0 {
+		banner.Hide()
+		return
+	}
+
+	if searchHasProblems(statuses) {
+		icon.SetResource(theme.WarningIcon())
+	} else {
+		icon.SetResource(theme.InfoIcon())
+	}
+	label.SetText(searchStatusSummary(statuses))
+	banner.Show()
+}
+
+// createDetailPane builds the pane showing the package details of the
+// selected app. It is hidden until the returned function is called with an app.
+func createDetailPane(appManager *AppManager, onClose func()) (*fyne.Container, func(app *AppInfo)) {
+	titleLabel := widget.NewLabel("")
+	titleLabel.TextStyle = fyne.TextStyle{Bold: true}
+	titleLabel.Wrapping = fyne.TextWrapWord
+
+	statusLabel := widget.NewLabel("")
+	statusLabel.TextStyle = fyne.TextStyle{Italic: true}
+	statusLabel.Wrapping = fyne.TextWrapWord
+
+	publisherLabel := widget.NewLabel("")
+	publisherLabel.Wrapping = fyne.TextWrapWord
+	homepageLink := widget.NewHyperlink("", nil)
+	licenseLabel := widget.NewLabel("")
+	licenseLabel.Wrapping = fyne.TextWrapWord
+	tagsLabel := widget.NewLabel("")
+	tagsLabel.Wrapping = fyne.TextWrapWord
+	descriptionLabel := widget.NewLabel("")
+	descriptionLabel.Wrapping = fyne.TextWrapWord
+	releaseNotesLabel := widget.NewLabel("")
+	releaseNotesLabel.Wrapping = fyne.TextWrapWord
+
+	form := widget.NewForm(
+		widget.NewFormItem("Publisher", publisherLabel),
+		widget.NewFormItem("Homepage", homepageLink),
+		widget.NewFormItem("License", licenseLabel),
+		widget.NewFormItem("Tags", tagsLabel),
+	)
+	form.Hide()
+
+	descriptionCard := widget.NewCard("Description", "", descriptionLabel)
+	descriptionCard.Hide()
+	releaseNotesCard := widget.NewCard("Release Notes", "", releaseNotesLabel)
+	releaseNotesCard.Hide()
+
+	var pane *fyne.Container
+	closeButton := widget.NewButtonWithIcon("", theme.CancelIcon(), func() {
+		pane.Hide()
+		if onClose != nil {
+			onClose()
+		}
+	})
+	closeButton.Importance = widget.LowImportance
+
+	// Fixed width so wrapped text has room, kept by a transparent spacer
+	spacer := canvas.NewRectangle(color.Transparent)
+	spacer.SetMinSize(fyne.NewSize(380, 0))
+
+	content := container.NewVScroll(container.NewVBox(
+		statusLabel,
+		form,
+		descriptionCard,
+		releaseNotesCard,
+	))
+	pane = container.NewStack(spacer, container.NewBorder(
+		container.NewVBox(
+			container.NewBorder(nil, nil, nil, closeButton, titleLabel),
+			widget.NewSeparator(),
+		),
+		nil, nil, nil,
+		content,
+	))
+	pane.Hide()
+
+	// The app shown last, so slow lookups don't overwrite a newer selection
+	var shownMutex sync.Mutex
+	var shown *AppInfo
+
+	render := func(app *AppInfo, details *PackageDetails) {
+		orDash := func(value string) string {
+			if value == "" {
+				return "-"
+			}
+			return value
+		}
+		publisherLabel.SetText(orDash(details.Publisher))
+		licenseLabel.SetText(orDash(details.License))
+		tagsLabel.SetText(orDash(strings.Join(details.Tags, ", ")))
+
+		homepageLink.SetText(orDash(details.Homepage))
+		if homepage, err := url.Parse(details.Homepage); err == nil && homepage.Scheme != "" {
+			homepageLink.SetURL(homepage)
+		} else {
+			homepageLink.SetURL(nil)
+		}
+
+		description := details.Description
+		if description == "" {
+			description = app.Description
+		}
+		descriptionLabel.SetText(description)
+		if description != "" {
+			descriptionCard.Show()
+		} else {
+			descriptionCard.Hide()
+		}
+
+		releaseNotesLabel.SetText(details.ReleaseNotes)
+		if details.ReleaseNotes != "" {
+			releaseNotesCard.Show()
+		} else {
+			releaseNotesCard.Hide()
+		}
+
+		statusLabel.Hide()
+		form.Show()
+	}
+
+	showDetails := func(app *AppInfo) {
+		shownMutex.Lock()
+		shown = app
+		shownMutex.Unlock()
+
+		titleLabel.SetText(fmt.Sprintf("%s (%s)", app.Name, app.Source))
+		statusLabel.SetText("Loading details...")
+		statusLabel.Show()
+		form.Hide()
+		descriptionCard.Hide()
+		releaseNotesCard.Hide()
+		pane.Show()
+
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					// Handle panic gracefully
+				}
+			}()
+
+			details, err := appManager.GetAppDetails(context.Background(), app)
+
+			shownMutex.Lock()
+			current := shown == app
+			shownMutex.Unlock()
+			if !current {
+				return
+			}
+
+			if details == nil {
+				statusLabel.SetText(fmt.Sprintf("Details are not available: %v", err))
+				return
+			}
+			render(app, details)
+		}()
+	}
+
+	return pane, showDetails
+}
+
 func createAppListItem() fyne.CanvasObject {
 	nameLabel := widget.NewLabel("")
 	nameLabel.TextStyle = fyne.TextStyle{Bold: true}
@@ -481,6 +1024,10 @@ func createAppListItem() fyne.CanvasObject {
 	installButton := widget.NewButtonWithIcon("Install", theme.DownloadIcon(), func() {})
 	saveButton := widget.NewButtonWithIcon("Save", theme.DocumentSaveIcon(), func() {})
 	removeButton := widget.NewButtonWithIcon("Remove", theme.DeleteIcon(), func() {})
+	upgradeButton := widget.NewButtonWithIcon("Upgrade", theme.MoveUpIcon(), func() {})
+	uninstallButton := widget.NewButtonWithIcon("Uninstall", theme.ContentRemoveIcon(), func() {})
+	uninstallButton.Importance = widget.DangerImportance
+	tagsButton := widget.NewButtonWithIcon("Edit Tags", theme.DocumentCreateIcon(), func() {})
 
 	statusIcon := widget.NewIcon(theme.InfoIcon())
 
@@ -500,22 +1047,127 @@ func createAppListItem() fyne.CanvasObject {
 		installButton,
 		saveButton,
 		removeButton,
+		upgradeButton,
+		uninstallButton,
+		tagsButton,
+	)
+
+	// Installation progress, only shown once an install has started
+	progressBar := widget.NewProgressBar()
+	phaseLabel := widget.NewLabel("")
+	logButton := widget.NewButtonWithIcon("Show Log", theme.ListIcon(), func() {})
+	logLabel := widget.NewLabel("")
+	logLabel.TextStyle = fyne.TextStyle{Monospace: true}
+	logLabel.Wrapping = fyne.TextWrapWord
+	logLabel.Hide()
+
+	progressRow := container.NewVBox(
+		container.NewBorder(nil, nil, phaseLabel, logButton, progressBar),
+		logLabel,
 	)
+	progressRow.Hide()
 
 	return container.NewVBox(
 		topRow,
 		buttonRow,
+		progressRow,
 		widget.NewSeparator(),
 	)
 }
 
+// installLogLines is how many lines of output an expanded install log shows
+const installLogLines = 15
+
+// expandedInstallLogs tracks which rows have their install log open, keyed by
+// install operation so the state survives row recycling
+var expandedInstallLogs sync.Map
+
+// updateInstallProgress renders the last known install progress of app
+func updateInstallProgress(obj fyne.CanvasObject, progressRow *fyne.Container, app *AppInfo, appManager *AppManager) {
+	if len(progressRow.Objects) < 2 {
+		return
+	}
+	barRow, ok := progressRow.Objects[0].(*fyne.Container)
+	if !ok || len(barRow.Objects) < 3 {
+		return
+	}
+	logLabel, ok := progressRow.Objects[1].(*widget.Label)
+	if !ok {
+		return
+	}
+
+	progress := appManager.GetInstallProgress(app)
+	if progress == nil {
+		progressRow.Hide()
+		return
+	}
+	progressRow.Show()
+
+	// Border layout keeps the center object first
+	if progressBar, ok := barRow.Objects[0].(*widget.ProgressBar); ok {
+		if progress.Percent >= 0 {
+			progressBar.SetValue(progress.Percent)
+		} else {
+			progressBar.SetValue(0)
+		}
+		if progress.Phase.finished() {
+			progressBar.Hide()
+		} else {
+			progressBar.Show()
+		}
+	}
+	if phaseLabel, ok := barRow.Objects[1].(*widget.Label); ok {
+		phaseText := string(progress.Phase)
+		if progress.Percent >= 0 && !progress.Phase.finished() {
+			phaseText = fmt.Sprintf("%s %.0f%%", progress.Phase, progress.Percent*100)
+		}
+		phaseLabel.SetText(phaseText)
+	}
+
+	key := installOperationKey(app)
+	_, expanded := expandedInstallLogs.Load(key)
+
+	if logButton, ok := barRow.Objects[2].(*widget.Button); ok {
+		if expanded {
+			logButton.SetText("Hide Log")
+		} else {
+			logButton.SetText("Show Log")
+		}
+		logButton.OnTapped = func() {
+			if expanded {
+				expandedInstallLogs.Delete(key)
+			} else {
+				expandedInstallLogs.Store(key, true)
+			}
+			updateInstallProgress(obj, progressRow, app, appManager)
+			// Let the list pick up the new row height
+			appManager.notifyProgressCallbacks()
+		}
+	}
+
+	if expanded {
+		lines := progress.Log
+		if len(lines) > installLogLines {
+			lines = lines[len(lines)-installLogLines:]
+		}
+		if len(lines) == 0 {
+			logLabel.SetText("No output yet")
+		} else {
+			logLabel.SetText(strings.Join(lines, "\n"))
+		}
+		logLabel.Show()
+	} else {
+		logLabel.Hide()
+	}
+}
+
 func updateAppListItem(obj fyne.CanvasObject, app *AppInfo, appManager *AppManager) {
 	if obj == nil || app == nil {
 		return
 	}
 
 	cont, ok := obj.(*fyne.Container)
-	if !ok || len(cont.Objects) < 2 {
+	if !ok || len(cont.Objects) < 3 {
 		return
 	}
 
@@ -525,7 +1177,7 @@ func updateAppListItem(obj fyne.CanvasObject, app *AppInfo, appManager *AppManag
 	}
 
 	buttonRow, ok := cont.Objects[1].(*fyne.Container)
-	if !ok || len(buttonRow.Objects) < 4 { // Now we have 4 elements (spacer + 3 buttons)
+	if !ok || len(buttonRow.Objects) < 7 { // Now we have 7 elements (spacer + 6 buttons)
 		return
 	}
 
@@ -544,15 +1196,32 @@ func updateAppListItem(obj fyne.CanvasObject, app *AppInfo, appManager *AppManag
 			nameLabel.SetText(app.Name)
 		}
 		if versionLabel, ok := labelContainer.Objects[1].(*widget.Label); ok {
-			versionLabel.SetText(fmt.Sprintf("Version: %s", app.Version))
+			versionText := fmt.Sprintf("Version: %s", app.Version)
+			if app.AvailableVersion != "" {
+				versionText = fmt.Sprintf("Version: %s → %s available", app.Version, app.AvailableVersion)
+			}
+			if required := requiredVersion(app); required != "" {
+				versionText += fmt.Sprintf(" • requires %s", required)
+			}
+			if app.Pinned {
+				versionText += " • pinned"
+			}
+			if versionMismatch(app) {
+				versionText += fmt.Sprintf(" • ⚠ installed %s differs", app.InstalledVersion)
+			}
+			versionLabel.SetText(versionText)
 		}
 		if packageIDLabel, ok := labelContainer.Objects[2].(*widget.Label); ok {
 			// Clean package ID display without list information
-			packageIDLabel.SetText(fmt.Sprintf("Package ID: %s", app.PackageID))
+			if app.Match != "" {
+				packageIDLabel.SetText(fmt.Sprintf("Package ID: %s (%s)", app.PackageID, app.Match))
+			} else {
+				packageIDLabel.SetText(fmt.Sprintf("Package ID: %s", app.PackageID))
+			}
 		}
 		if listsLabel, ok := labelContainer.Objects[3].(*widget.Label); ok {
 			// Show which lists contain this app
-			listsContaining, err := appManager.GetAppListsContaining(app.PackageID)
+			listsContaining, err := appManager.GetAppListsContaining(app.Source, app.PackageID)
 			listsText := "Not in any lists"
 
 			if err == nil && len(listsContaining) > 0 {
@@ -562,13 +1231,24 @@ func updateAppListItem(obj fyne.CanvasObject, app *AppInfo, appManager *AppManag
 				}
 				listsText = fmt.Sprintf("In lists: %s", strings.Join(listNames, ", "))
 			}
+			if len(app.Tags) > 0 {
+				listsText += fmt.Sprintf(" • 🏷 %s", formatTags(app.Tags))
+			}
 
 			listsLabel.SetText(listsText)
 		}
 	}
 
 	if sourceLabel, ok := topRow.Objects[3].(*widget.Label); ok {
-		sourceLabel.SetText(fmt.Sprintf("Source: %s", app.Source))
+		if app.Repository != "" && app.Repository != app.Source {
+			sourceLabel.SetText(fmt.Sprintf("Source: %s (%s)", app.Source, app.Repository))
+		} else {
+			sourceLabel.SetText(fmt.Sprintf("Source: %s", app.Source))
+		}
+	}
+
+	if progressRow, ok := cont.Objects[2].(*fyne.Container); ok {
+		updateInstallProgress(obj, progressRow, app, appManager)
 	}
 
 	// Get current view filter to determine which buttons to show
@@ -578,23 +1258,42 @@ func updateAppListItem(obj fyne.CanvasObject, app *AppInfo, appManager *AppManag
 	if installButton, ok := buttonRow.Objects[1].(*widget.Button); ok {
 		if app.IsInstalled {
 			installButton.SetText("Installed")
+			installButton.SetIcon(theme.DownloadIcon())
 			installButton.Disable()
+		} else if appManager.IsInstalling(app) {
+			// The row was recycled while an install is running
+			installButton.SetText("Cancel Install")
+			installButton.SetIcon(theme.CancelIcon())
+			installButton.Enable()
+			installButton.OnTapped = func() {
+				installButton.SetText("Cancelling...")
+				installButton.Disable()
+				appManager.CancelInstall(app)
+			}
 		} else {
 			installButton.SetText("Install")
+			installButton.SetIcon(theme.DownloadIcon())
 			installButton.Enable()
 			installButton.OnTapped = func() {
-				installButton.SetText("Installing...")
-				installButton.Disable()
+				// While installing the same button cancels the installation
+				installButton.SetText("Cancel Install")
+				installButton.SetIcon(theme.CancelIcon())
+				installButton.OnTapped = func() {
+					installButton.SetText("Cancelling...")
+					installButton.Disable()
+					appManager.CancelInstall(app)
+				}
 
 				go func() {
 					defer func() {
 						if r := recover(); r != nil {
 							installButton.SetText("Install")
+							installButton.SetIcon(theme.DownloadIcon())
 							installButton.Enable()
 						}
 					}()
 
-					err := appManager.InstallApp(app)
+					err := appManager.InstallApp(context.Background(), app)
 
 					// Get the main window for dialogs
 					windows := fyne.CurrentApp().Driver().AllWindows()
@@ -603,12 +1302,20 @@ func updateAppListItem(obj fyne.CanvasObject, app *AppInfo, appManager *AppManag
 					}
 					mainWindow := windows[0]
 
-					if err != nil {
-						dialog.ShowError(err, mainWindow)
+					if err != nil && !IsSoftFailure(err) {
+						showOperationError(err, mainWindow)
 						installButton.SetText("Install")
+						installButton.SetIcon(theme.DownloadIcon())
 						installButton.Enable()
 					} else {
+						if errors.Is(err, ErrRebootRequired) {
+							showRestartPrompt(mainWindow, appManager, []*AppInfo{app})
+						} else if err != nil {
+							showOperationError(err, mainWindow)
+						}
 						installButton.SetText("Installed")
+						installButton.SetIcon(theme.DownloadIcon())
+						installButton.Disable()
 						app.IsInstalled = true
 					}
 				}()
@@ -640,7 +1347,7 @@ func updateAppListItem(obj fyne.CanvasObject, app *AppInfo, appManager *AppManag
 						}
 					}()
 
-					err := appManager.RemoveSavedApp(app.PackageID)
+					err := appManager.RemoveSavedApp(app.Source, app.PackageID)
 
 					// Get the main window for dialogs
 					windows := fyne.CurrentApp().Driver().AllWindows()
@@ -674,7 +1381,7 @@ func updateAppListItem(obj fyne.CanvasObject, app *AppInfo, appManager *AppManag
 			// Show as Save button when not viewing saved apps
 			if app.IsSaved {
 				// Check which lists contain this app
-				listsContaining, err := appManager.GetAppListsContaining(app.PackageID)
+				listsContaining, err := appManager.GetAppListsContaining(app.Source, app.PackageID)
 				if err == nil && len(listsContaining) > 0 {
 					if len(listsContaining) == 1 {
 						actionButton.SetText(fmt.Sprintf("Saved in %s", listsContaining[0].Name))
@@ -743,10 +1450,288 @@ func updateAppListItem(obj fyne.CanvasObject, app *AppInfo, appManager *AppManag
 					updateAppListItem(obj, app, appManager)
 				})
 			}
+		} else if currentViewFilter == "Saved Apps" {
+			// Saved apps carry their own version and installer options
+			optionsText := "Install Options"
+			if required := requiredVersion(app); required != "" {
+				optionsText = fmt.Sprintf("Install Options (%s)", required)
+			}
+			if app.Pinned {
+				optionsText += " 📌"
+			}
+			manageButton.SetText(optionsText)
+			manageButton.SetIcon(theme.SettingsIcon())
+			manageButton.Show()
+			manageButton.Enable()
+			manageButton.OnTapped = func() {
+				// Get the main window for dialogs
+				windows := fyne.CurrentApp().Driver().AllWindows()
+				if len(windows) == 0 {
+					return
+				}
+				mainWindow := windows[0]
+
+				showInstallOptionsDialog(mainWindow, appManager, app)
+			}
 		} else {
 			manageButton.Hide()
 		}
 	}
+
+	// Upgrade button (button index 4) - only meaningful for installed apps
+	if upgradeButton, ok := buttonRow.Objects[4].(*widget.Button); ok {
+		if app.IsInstalled {
+			upgradeText := "Upgrade"
+			if app.AvailableVersion != "" {
+				upgradeText = fmt.Sprintf("Upgrade to %s", app.AvailableVersion)
+			}
+			upgradeButton.SetText(upgradeText)
+			upgradeButton.Show()
+			upgradeButton.Enable()
+			upgradeButton.OnTapped = func() {
+				upgradeButton.SetText("Upgrading...")
+				upgradeButton.Disable()
+
+				go func() {
+					defer func() {
+						if r := recover(); r != nil {
+							upgradeButton.SetText(upgradeText)
+							upgradeButton.Enable()
+						}
+					}()
+
+					err := appManager.UpgradeApp(context.Background(), app)
+
+					// Get the main window for dialogs
+					windows := fyne.CurrentApp().Driver().AllWindows()
+					if len(windows) == 0 {
+						return
+					}
+					mainWindow := windows[0]
+
+					upgradeButton.SetText(upgradeText)
+					upgradeButton.Enable()
+
+					if errors.Is(err, ErrRebootRequired) {
+						showRestartPrompt(mainWindow, appManager, []*AppInfo{app})
+					} else if err != nil {
+						showOperationError(err, mainWindow)
+					} else {
+						dialog.ShowInformation("Upgraded",
+							fmt.Sprintf("Application '%s' has been upgraded.", app.Name),
+							mainWindow)
+					}
+				}()
+			}
+		} else {
+			upgradeButton.Hide()
+		}
+	}
+
+	// Uninstall button (button index 5) - only meaningful for installed apps
+	if uninstallButton, ok := buttonRow.Objects[5].(*widget.Button); ok {
+		if app.IsInstalled {
+			uninstallButton.SetText("Uninstall")
+			uninstallButton.Show()
+			uninstallButton.Enable()
+			uninstallButton.OnTapped = func() {
+				// Get the main window for dialogs
+				windows := fyne.CurrentApp().Driver().AllWindows()
+				if len(windows) == 0 {
+					return
+				}
+				mainWindow := windows[0]
+
+				dialog.ShowConfirm("Uninstall Application",
+					fmt.Sprintf("Are you sure you want to uninstall '%s'?", app.Name),
+					func(confirmed bool) {
+						if !confirmed {
+							return
+						}
+
+						uninstallButton.SetText("Uninstalling...")
+						uninstallButton.Disable()
+
+						go func() {
+							defer func() {
+								if r := recover(); r != nil {
+									uninstallButton.SetText("Uninstall")
+									uninstallButton.Enable()
+								}
+							}()
+
+							err := appManager.UninstallApp(context.Background(), app)
+
+							uninstallButton.SetText("Uninstall")
+							uninstallButton.Enable()
+
+							if err != nil {
+								showOperationError(err, mainWindow)
+							} else {
+								app.IsInstalled = false
+								dialog.ShowInformation("Uninstalled",
+									fmt.Sprintf("Application '%s' has been uninstalled.", app.Name),
+									mainWindow)
+							}
+						}()
+					}, mainWindow)
+			}
+		} else {
+			uninstallButton.Hide()
+		}
+	}
+
+	// Tags button (button index 6) - tags belong to the app saved in the current list
+	if tagsButton, ok := buttonRow.Objects[6].(*widget.Button); ok {
+		if currentViewFilter == "Saved Apps" {
+			tagsButton.Show()
+			tagsButton.OnTapped = func() {
+				// Get the main window for dialogs
+				windows := fyne.CurrentApp().Driver().AllWindows()
+				if len(windows) == 0 {
+					return
+				}
+				mainWindow := windows[0]
+
+				showEditTagsDialog(mainWindow, appManager, app)
+			}
+		} else {
+			tagsButton.Hide()
+		}
+	}
+}
+
+// showEditTagsDialog edits the tags of a saved app, typed comma separated
+func showEditTagsDialog(parent fyne.Window, appManager *AppManager, app *AppInfo) {
+	tagsEntry := widget.NewEntry()
+	tagsEntry.SetPlaceHolder("e.g. dev, needs-license, security-tool")
+	tagsEntry.SetText(formatTags(app.Tags))
+
+	hintText := "Separate tags with commas. Tags are stored in lower case."
+	if existing := appManager.GetTags(); len(existing) > 0 {
+		hintText += fmt.Sprintf("\nTags in use: %s", formatTags(existing))
+	}
+	hint := widget.NewLabel(hintText)
+	hint.Wrapping = fyne.TextWrapWord
+
+	items := []*widget.FormItem{
+		{Text: "Tags", Widget: tagsEntry},
+		{Text: "", Widget: hint},
+	}
+
+	formDialog := dialog.NewForm(fmt.Sprintf("Tags for %s", app.Name), "Save", "Cancel", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					// Handle panic gracefully
+				}
+			}()
+
+			err := appManager.SetAppTags(app, parseTags(tagsEntry.Text))
+			if err != nil {
+				dialog.ShowError(err, parent)
+			}
+		}()
+	}, parent)
+	formDialog.Resize(fyne.NewSize(480, 0))
+	formDialog.Show()
+}
+
+// showInstallOptionsDialog edits how a saved app is installed from its list:
+// the version, pinning and the options passed to the installer
+func showInstallOptionsDialog(parent fyne.Window, appManager *AppManager, app *AppInfo) {
+	versionEntry := widget.NewEntry()
+	versionEntry.SetPlaceHolder("latest")
+	if required := requiredVersion(app); required != "" {
+		versionEntry.SetText(required)
+	}
+
+	pinCheck := widget.NewCheck("Pin this version so upgrades skip it", nil)
+	pinCheck.SetChecked(app.Pinned)
+
+	// Empty selections fall back to the backend defaults
+	const defaultOption = "Default"
+	scopeSelect := widget.NewSelect(append([]string{defaultOption}, installScopes...), nil)
+	scopeSelect.SetSelected(defaultOption)
+	if app.Options.Scope != "" {
+		scopeSelect.SetSelected(app.Options.Scope)
+	}
+
+	architectureSelect := widget.NewSelect(append([]string{defaultOption}, installArchitectures...), nil)
+	architectureSelect.SetSelected(defaultOption)
+	if app.Options.Architecture != "" {
+		architectureSelect.SetSelected(app.Options.Architecture)
+	}
+
+	locationEntry := widget.NewEntry()
+	locationEntry.SetPlaceHolder("Installer default")
+	locationEntry.SetText(app.Options.Location)
+
+	overrideEntry := widget.NewEntry()
+	overrideEntry.SetPlaceHolder("Replaces the installer arguments")
+	overrideEntry.SetText(app.Options.Override)
+
+	customArgsEntry := widget.NewEntry()
+	customArgsEntry.SetPlaceHolder("Added to the installer arguments")
+	customArgsEntry.SetText(app.Options.CustomArgs)
+
+	interactiveCheck := widget.NewCheck("Show the installer instead of installing silently", nil)
+	interactiveCheck.SetChecked(app.Options.Interactive)
+
+	hint := widget.NewLabel(fmt.Sprintf("Leave the version empty to always install the latest one. Last seen version: %s", app.Version))
+	hint.Wrapping = fyne.TextWrapWord
+
+	items := []*widget.FormItem{
+		{Text: "Version", Widget: versionEntry},
+		{Text: "", Widget: pinCheck},
+		{Text: "Scope", Widget: scopeSelect},
+		{Text: "Architecture", Widget: architectureSelect},
+		{Text: "Location", Widget: locationEntry},
+		{Text: "Override", Widget: overrideEntry},
+		{Text: "Custom args", Widget: customArgsEntry},
+		{Text: "", Widget: interactiveCheck},
+		{Text: "", Widget: hint},
+	}
+
+	formDialog := dialog.NewForm(fmt.Sprintf("Install Options for %s", app.Name), "Save", "Cancel", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		selected := func(value string) string {
+			if value == defaultOption {
+				return ""
+			}
+			return value
+		}
+		options := InstallOptions{
+			Scope:        selected(scopeSelect.Selected),
+			Architecture: selected(architectureSelect.Selected),
+			Location:     strings.TrimSpace(locationEntry.Text),
+			Override:     strings.TrimSpace(overrideEntry.Text),
+			CustomArgs:   strings.TrimSpace(customArgsEntry.Text),
+			Interactive:  interactiveCheck.Checked,
+		}
+
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					// Handle panic gracefully
+				}
+			}()
+
+			err := appManager.SetInstallOptions(context.Background(), app, strings.TrimSpace(versionEntry.Text), pinCheck.Checked, options)
+			if err != nil {
+				showOperationError(err, parent)
+			}
+		}()
+	}, parent)
+	formDialog.Resize(fyne.NewSize(560, 0))
+	formDialog.Show()
 }
 
 func updateEmptyStateMessage(messageLabel *widget.Label, appManager *AppManager) {
@@ -795,6 +1780,14 @@ func updateEmptyStateMessage(messageLabel *widget.Label, appManager *AppManager)
 				message = fmt.Sprintf("No %s applications found in list: %s\nTry searching with different terms or save some %s apps to this list", currentSourceFilter, listName, currentSourceFilter)
 			}
 		}
+	} else if currentViewFilter == "Updates Available" {
+		if isSearching {
+			message = fmt.Sprintf("No application found: \"%s\"\nNo pending updates match your search", currentSearchQuery)
+		} else if currentSourceFilter == "All Sources" {
+			message = "All installed applications are up to date"
+		} else {
+			message = fmt.Sprintf("All installed %s applications are up to date", currentSourceFilter)
+		}
 	} else {
 		// All Results
 		if isSearching {
@@ -831,11 +1824,12 @@ func showListManagementDialog(parent fyne.Window, appManager *AppManager, update
 		},
 		func() fyne.CanvasObject {
 			return container.NewHBox(
-				widget.NewLabel(""),             // Name
-				widget.NewLabel(""),             // Description
-				widget.NewButton("Edit", nil),   // Edit button
-				widget.NewButton("Delete", nil), // Delete button
-				widget.NewButton("Export", nil), // Export button
+				widget.NewLabel(""),                     // Name
+				widget.NewLabel(""),                     // Description
+				widget.NewButton("Edit", nil),           // Edit button
+				widget.NewButton("Delete", nil),         // Delete button
+				widget.NewButton("Export", nil),         // Export button
+				widget.NewButton("Set as default", nil), // Set as default button
 			)
 		},
 		func(id widget.ListItemID, obj fyne.CanvasObject) {
@@ -849,8 +1843,13 @@ func showListManagementDialog(parent fyne.Window, appManager *AppManager, update
 				editBtn := cont.Objects[2].(*widget.Button)
 				deleteBtn := cont.Objects[3].(*widget.Button)
 				exportBtn := cont.Objects[4].(*widget.Button)
+				defaultBtn := cont.Objects[5].(*widget.Button)
 
-				nameLabel.SetText(list.Name)
+				if list.IsDefault {
+					nameLabel.SetText(list.Name + " (default)")
+				} else {
+					nameLabel.SetText(list.Name)
+				}
 				descLabel.SetText(list.Description)
 
 				editBtn.OnTapped = func() {
@@ -861,8 +1860,8 @@ func showListManagementDialog(parent fyne.Window, appManager *AppManager, update
 				}
 
 				deleteBtn.OnTapped = func() {
-					if list.ID == 1 { // Default list
-						dialog.ShowError(fmt.Errorf("Cannot delete the default list"), listWindow)
+					if list.IsDefault {
+						dialog.ShowError(fmt.Errorf("Cannot delete the default list, set another list as the default first"), listWindow)
 						return
 					}
 
@@ -906,11 +1905,23 @@ func showListManagementDialog(parent fyne.Window, appManager *AppManager, update
 					}()
 				}
 
+				defaultBtn.OnTapped = func() {
+					err := appManager.SetDefaultList(list.ID)
+					if err != nil {
+						dialog.ShowError(err, listWindow)
+						return
+					}
+					listsList.Refresh()
+					updateCallback()
+				}
+
 				// Disable delete button for default list
-				if list.ID == 1 {
+				if list.IsDefault {
 					deleteBtn.Disable()
+					defaultBtn.Disable()
 				} else {
 					deleteBtn.Enable()
+					defaultBtn.Enable()
 				}
 			}
 		},
@@ -1066,7 +2077,7 @@ func showEditListDialog(parent fyne.Window, appManager *AppManager, list *AppLis
 	}
 
 	// Disable name editing for default list
-	if list.ID == 1 {
+	if list.IsDefault {
 		nameEntry.Disable()
 	}
 
@@ -1168,7 +2179,7 @@ func showAppListManagementDialog(parent fyne.Window, appManager *AppManager, app
 
 	// Get all lists and which ones contain this app
 	allLists := appManager.GetLists()
-	listsContaining, _ := appManager.GetAppListsContaining(app.PackageID)
+	listsContaining, _ := appManager.GetAppListsContaining(app.Source, app.PackageID)
 
 	// Create a map for quick lookup
 	containingMap := make(map[int64]bool)
@@ -1224,7 +2235,7 @@ func showAppListManagementDialog(parent fyne.Window, appManager *AppManager, app
 				}
 			} else if wasInList && !isChecked {
 				// Remove from list
-				err := appManager.RemoveAppFromList(app.PackageID, list.ID)
+				err := appManager.RemoveAppFromList(app.Source, app.PackageID, list.ID)
 				if err == nil {
 					removed = append(removed, list.Name)
 				}
@@ -1501,3 +2512,297 @@ func showImportResultsDialog(parent fyne.Window, results []ImportResult, updateC
 	resultsWindow.SetContent(content)
 	resultsWindow.Show()
 }
+
+// historyPeriods are the date filters of the History window
+var historyPeriods = []struct {
+	Label string
+	Days  int // 0 for all time
+}{
+	{"Today", 1},
+	{"Last 7 days", 7},
+	{"Last 30 days", 30},
+	{"All time", 0},
+}
+
+// showHistoryWindow lists past installations with their outcome and captured
+// output, filtered by date, outcome and list
+func showHistoryWindow(parent fyne.Window, appManager *AppManager) {
+	historyWindow := fyne.CurrentApp().NewWindow("Install History")
+	historyWindow.Resize(fyne.NewSize(900, 600))
+	historyWindow.CenterOnScreen()
+
+	var records []*InstallRecord
+	var selected *InstallRecord
+
+	periodLabels := make([]string, 0, len(historyPeriods))
+	for _, period := range historyPeriods {
+		periodLabels = append(periodLabels, period.Label)
+	}
+	periodSelect := widget.NewSelect(periodLabels, nil)
+
+	const allOutcomes = "All Outcomes"
+	outcomeSelect := widget.NewSelect([]string{allOutcomes, string(OutcomeSucceeded), string(OutcomeFailed),
+		string(OutcomeCancelled), string(OutcomeRestart)}, nil)
+
+	const allLists = "All Lists"
+	lists := appManager.GetLists()
+	listNames := []string{allLists}
+	for _, list := range lists {
+		listNames = append(listNames, list.Name)
+	}
+	listSelect := widget.NewSelect(listNames, nil)
+
+	summaryLabel := widget.NewLabel("")
+
+	detailsLabel := widget.NewLabel("Select an installation to see its output")
+	detailsLabel.Wrapping = fyne.TextWrapWord
+	detailsLabel.TextStyle = fyne.TextStyle{Monospace: true}
+
+	copyButton := widget.NewButtonWithIcon("Copy Details", theme.ContentCopyIcon(), func() {
+		if selected != nil {
+			historyWindow.Clipboard().SetContent(formatInstallRecord(selected))
+		}
+	})
+	copyButton.Disable()
+
+	historyList := widget.NewList(
+		func() int {
+			return len(records)
+		},
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil, widget.NewIcon(theme.ConfirmIcon()), nil, widget.NewLabel(""))
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			defer func() {
+				if r := recover(); r != nil {
+					// Handle panic gracefully
+				}
+			}()
+			if id < 0 || id >= len(records) {
+				return
+			}
+			record := records[id]
+			row := obj.(*fyne.Container)
+
+			// Border layout keeps the center object first
+			if label, ok := row.Objects[0].(*widget.Label); ok {
+				list := record.ListName
+				if list == "" {
+					list = "search results"
+				}
+				label.SetText(fmt.Sprintf("%s   %s (%s) %s   %s   %s   %s",
+					record.StartedAt.Local().Format("2006-01-02 15:04"), record.Name, record.PackageID,
+					record.Version, record.Source, list, record.Outcome))
+			}
+			if icon, ok := row.Objects[1].(*widget.Icon); ok {
+				switch record.Outcome {
+				case OutcomeSucceeded:
+					icon.SetResource(theme.ConfirmIcon())
+				case OutcomeRestart:
+					icon.SetResource(theme.WarningIcon())
+				case OutcomeCancelled:
+					icon.SetResource(theme.CancelIcon())
+				default:
+					icon.SetResource(theme.ErrorIcon())
+				}
+			}
+		},
+	)
+	historyList.OnSelected = func(id widget.ListItemID) {
+		if id < 0 || id >= len(records) {
+			return
+		}
+		selected = records[id]
+		detailsLabel.SetText(formatInstallRecord(selected))
+		copyButton.Enable()
+	}
+
+	reload := func() {
+		filter := HistoryFilter{}
+		for _, period := range historyPeriods {
+			if period.Label == periodSelect.Selected && period.Days > 0 {
+				now := time.Now()
+				today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+				filter.Since = today.AddDate(0, 0, 1-period.Days)
+			}
+		}
+		if outcomeSelect.Selected != allOutcomes {
+			filter.Outcome = InstallOutcome(outcomeSelect.Selected)
+		}
+		for _, list := range lists {
+			if list.Name == listSelect.Selected {
+				filter.ListID = list.ID
+			}
+		}
+
+		loaded, err := appManager.GetInstallHistory(filter)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to load the install history: %v", err), historyWindow)
+			return
+		}
+		records = loaded
+		selected = nil
+		copyButton.Disable()
+		detailsLabel.SetText("Select an installation to see its output")
+		summaryLabel.SetText(fmt.Sprintf("%d installations", len(records)))
+		historyList.UnselectAll()
+		historyList.Refresh()
+	}
+
+	periodSelect.SetSelected("Last 30 days")
+	outcomeSelect.SetSelected(allOutcomes)
+	listSelect.SetSelected(allLists)
+	// Filters reload once their initial values are set
+	periodSelect.OnChanged = func(string) { reload() }
+	outcomeSelect.OnChanged = func(string) { reload() }
+	listSelect.OnChanged = func(string) { reload() }
+	reload()
+
+	filters := container.NewHBox(
+		widget.NewLabel("Date:"), periodSelect,
+		widget.NewLabel("Outcome:"), outcomeSelect,
+		widget.NewLabel("List:"), listSelect,
+		widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), reload),
+	)
+
+	details := container.NewBorder(nil, container.NewHBox(copyButton), nil, nil, container.NewVScroll(detailsLabel))
+	split := container.NewVSplit(historyList, details)
+	split.SetOffset(0.55)
+
+	content := container.NewBorder(
+		container.NewVBox(filters, summaryLabel, widget.NewSeparator()), // top
+		nil,   // bottom
+		nil,   // left
+		nil,   // right
+		split, // center
+	)
+
+	historyWindow.SetContent(content)
+	historyWindow.Show()
+}
+
+// showBatchReport lists what happened to every app of a batch install. The
+// report can be exported, and retry installs the failed apps again.
+func showBatchReport(parent fyne.Window, appManager *AppManager, report *BatchReport, retry func(failed []*AppInfo)) {
+	title := "Install Report"
+	if report.ListName != "" {
+		title = fmt.Sprintf("Install Report - %s", report.ListName)
+	}
+	reportWindow := fyne.CurrentApp().NewWindow(title)
+	reportWindow.Resize(fyne.NewSize(800, 500))
+	reportWindow.CenterOnScreen()
+
+	summaryLabel := widget.NewLabel(fmt.Sprintf("%s in %s", report.Summary(),
+		report.FinishedAt.Sub(report.StartedAt).Round(time.Second)))
+	summaryLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	resultList := widget.NewList(
+		func() int {
+			return len(report.Results)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return container.NewBorder(nil, nil, widget.NewIcon(theme.ConfirmIcon()), nil, label)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			defer func() {
+				if r := recover(); r != nil {
+					// Handle panic gracefully
+				}
+			}()
+			if id < 0 || id >= len(report.Results) {
+				return
+			}
+			result := report.Results[id]
+			row := obj.(*fyne.Container)
+
+			// Border layout keeps the center object first
+			if label, ok := row.Objects[0].(*widget.Label); ok {
+				text := fmt.Sprintf("%s (%s) %s   %s   %s", result.Name, result.PackageID, result.Version, result.Source, result.Outcome)
+				if result.Reason != "" {
+					text += "   " + result.Reason
+				}
+				label.SetText(text)
+			}
+			if icon, ok := row.Objects[1].(*widget.Icon); ok {
+				switch result.Outcome {
+				case BatchInstalled, BatchAlreadyInstalled:
+					icon.SetResource(theme.ConfirmIcon())
+				case BatchRestartRequired:
+					icon.SetResource(theme.WarningIcon())
+				case BatchSkipped:
+					icon.SetResource(theme.MediaSkipNextIcon())
+				default:
+					icon.SetResource(theme.ErrorIcon())
+				}
+			}
+		},
+	)
+
+	export := func(format string) {
+		path, err := appManager.ExportBatchReport(report, format)
+		if err != nil {
+			dialog.ShowError(err, reportWindow)
+			return
+		}
+		dialog.ShowInformation("Report Exported", fmt.Sprintf("The report was saved to:\n%s", path), reportWindow)
+	}
+	exportCSVButton := widget.NewButtonWithIcon("Export CSV", theme.DocumentSaveIcon(), func() { export("csv") })
+	exportJSONButton := widget.NewButtonWithIcon("Export JSON", theme.DocumentSaveIcon(), func() { export("json") })
+
+	failed := report.FailedApps()
+	retryButton := widget.NewButtonWithIcon(fmt.Sprintf("Retry Failed (%d)", len(failed)), theme.ViewRefreshIcon(), nil)
+	retryButton.Importance = widget.HighImportance
+	retryButton.OnTapped = func() {
+		reportWindow.Close()
+		retry(failed)
+	}
+	if len(failed) == 0 {
+		retryButton.Disable()
+	}
+
+	buttons := container.NewHBox(
+		exportCSVButton,
+		exportJSONButton,
+		layout.NewSpacer(),
+		retryButton,
+		widget.NewButton("Close", func() { reportWindow.Close() }),
+	)
+
+	content := container.NewBorder(
+		container.NewVBox(summaryLabel, widget.NewSeparator()), // top
+		buttons,    // bottom
+		nil,        // left
+		nil,        // right
+		resultList, // center
+	)
+
+	reportWindow.SetContent(content)
+	reportWindow.Show()
+
+	// One prompt for every app of this batch that needs a restart
+	showRestartPrompt(parent, appManager, report.RestartRequiredApps())
+}
+
+// formatInstallRecord describes an installation for the details pane and for
+// pasting into support tickets
+func formatInstallRecord(record *InstallRecord) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Package:  %s (%s)\n", record.Name, record.PackageID)
+	fmt.Fprintf(&b, "Source:   %s\n", record.Source)
+	fmt.Fprintf(&b, "Version:  %s\n", record.Version)
+	if record.ListName != "" {
+		fmt.Fprintf(&b, "List:     %s\n", record.ListName)
+	}
+	fmt.Fprintf(&b, "Started:  %s\n", record.StartedAt.Local().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "Duration: %s\n", record.FinishedAt.Sub(record.StartedAt).Round(time.Second))
+	fmt.Fprintf(&b, "Outcome:  %s\n", record.Outcome)
+	if record.Error != "" {
+		fmt.Fprintf(&b, "Error:    %s\n", record.Error)
+	}
+	if record.Log != "" {
+		fmt.Fprintf(&b, "\n%s\n", record.Log)
+	}
+	return b.String()
+}